@@ -0,0 +1,110 @@
+package odoosearchdomain
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultDomainCacheCapacity is used by NewDomainCache for a non-positive
+// capacity.
+const DefaultDomainCacheCapacity = 1024
+
+// DomainCache is a bounded LRU cache in front of ParseDomainWithOptions,
+// keyed by the input string and the parse options. It is safe for
+// concurrent use. Parse errors are cached like results, and every call
+// returns its own deep copy, so callers may modify the returned domain
+// freely.
+type DomainCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[cacheKey]*list.Element
+	order    *list.List // most recently used first
+	hits     uint64
+	misses   uint64
+}
+
+type cacheKey struct {
+	domain string
+	opts   ParseOptions
+}
+
+type cacheEntry struct {
+	key   cacheKey
+	terms []any
+	err   error
+}
+
+// CacheStats reports the activity of a DomainCache.
+type CacheStats struct {
+	Hits     uint64
+	Misses   uint64
+	Entries  int
+	Capacity int
+}
+
+// NewDomainCache returns a cache holding at most capacity parsed domains.
+func NewDomainCache(capacity int) *DomainCache {
+	if capacity <= 0 {
+		capacity = DefaultDomainCacheCapacity
+	}
+	return &DomainCache{
+		capacity: capacity,
+		entries:  make(map[cacheKey]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// Parse is the cached equivalent of ParseDomain.
+func (c *DomainCache) Parse(domain string) ([]any, error) {
+	return c.ParseWithOptions(domain, ParseOptions{})
+}
+
+// ParseWithOptions is the cached equivalent of ParseDomainWithOptions.
+func (c *DomainCache) ParseWithOptions(domain string, opts ParseOptions) ([]any, error) {
+	key := cacheKey{domain: domain, opts: opts}
+
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		c.hits++
+		entry := el.Value.(*cacheEntry)
+		c.mu.Unlock()
+		return cloneDomain(entry.terms), entry.err
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// Parse outside the lock; concurrent misses on the same key may both
+	// parse, and the later one refreshes the entry.
+	terms, err := ParseDomainWithOptions(domain, opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+	} else {
+		c.entries[key] = c.order.PushFront(&cacheEntry{key: key, terms: terms, err: err})
+		if c.order.Len() > c.capacity {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*cacheEntry).key)
+		}
+	}
+	return cloneDomain(terms), err
+}
+
+// Stats returns the hit and miss counters and the current size.
+func (c *DomainCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Hits: c.hits, Misses: c.misses, Entries: c.order.Len(), Capacity: c.capacity}
+}
+
+// Reset empties the cache and its counters.
+func (c *DomainCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	c.order.Init()
+	c.hits, c.misses = 0, 0
+}
@@ -0,0 +1,99 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestDomainCache(t *testing.T) {
+	c := NewDomainCache(2)
+	const a = "[('a','=',1),('tags','in',[1,2])]"
+	const b = "[('b','=',2)]"
+	const d = "[('d','=',4)]"
+
+	first, err := c.Parse(a)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// Mutating a result must not affect the cache.
+	first[0].([]any)[0] = "mutated"
+	first[1].([]any)[2].([]any)[0] = 99
+	second, _ := c.Parse(a)
+	expected, _ := ParseDomain(a)
+	if !reflect.DeepEqual(expected, second) {
+		t.Errorf("expected: %v\n     got: %v", expected, second)
+	}
+
+	c.Parse(b)
+	c.Parse(a) // a is now the most recently used
+	c.Parse(d) // evicts b
+	if got := c.Stats(); got != (CacheStats{Hits: 2, Misses: 3, Entries: 2, Capacity: 2}) {
+		t.Errorf("unexpected stats %+v", got)
+	}
+	c.Parse(b)
+	if got := c.Stats(); got.Misses != 4 {
+		t.Errorf("expected b to have been evicted, stats %+v", got)
+	}
+
+	// Options are part of the key, and errors are cached.
+	opts := ParseOptions{MaxLeaves: 1}
+	if _, err := c.ParseWithOptions(a, opts); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+	if _, err := c.ParseWithOptions(a, opts); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected cached ErrLimitExceeded, got %v", err)
+	}
+	if got := c.Stats(); got.Hits != 3 || got.Misses != 5 {
+		t.Errorf("unexpected stats %+v", got)
+	}
+
+	c.Reset()
+	if got := c.Stats(); got != (CacheStats{Capacity: 2}) {
+		t.Errorf("unexpected stats after reset %+v", got)
+	}
+}
+
+func TestDomainCacheConcurrent(t *testing.T) {
+	c := NewDomainCache(8)
+	domains := make([]string, 16)
+	for i := range domains {
+		domains[i] = fmt.Sprintf("[('id','=',%d),'|',('a','in',[1,2]),('b','=',False)]", i)
+	}
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 500 {
+				domain := domains[(g+i)%len(domains)]
+				got, err := c.Parse(domain)
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+					return
+				}
+				got[0].([]any)[2] = -1 // callers own their copy
+				if expected, _ := ParseDomain(domain); reflect.DeepEqual(expected, got) {
+					t.Errorf("copy shares state with the cache")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	stats := c.Stats()
+	if stats.Hits+stats.Misses != 8*500 || stats.Entries > 8 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func BenchmarkDomainCache_Hit(b *testing.B) {
+	c := NewDomainCache(16)
+	const domain = "[('name', 'like', 'John'),('ref', 'not like', 12345),('value','=',123.45),'|', ('is_company', '=', True),('customer','=',True)]"
+	c.Parse(domain)
+	for b.Loop() {
+		c.Parse(domain)
+	}
+}
@@ -0,0 +1,224 @@
+package odoosearchdomain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Canonicalize returns a stable representation of a domain so that domains
+// differing only in presentation compare equal. It
+//
+//   - makes the implicit top-level AND explicit and flattens nested '&'/'|',
+//   - sorts and deduplicates the operands of '&' and '|',
+//   - pushes '!' down to the leaves, removing double negations and flipping
+//     operators that have an exact complement,
+//   - normalizes values: integral floats become ints, other integer and float
+//     types become int and float64, and 'in'/'not in' lists are sorted and
+//     deduplicated (a scalar becomes a one-element list),
+//   - canonicalizes nested domains of 'any' and 'not any' leaves.
+func Canonicalize(terms []any) ([]any, error) {
+	tree, err := buildTree(terms)
+	if err != nil {
+		return []any{}, err
+	}
+	if tree == nil {
+		return []any{}, nil
+	}
+	canon, err := canonicalNode(tree.nnf())
+	if err != nil {
+		return []any{}, err
+	}
+	return canon.toDomain(), nil
+}
+
+// Fingerprint returns a hex-encoded SHA-256 hash of the canonical form of a
+// domain, suitable as a cache key.
+func Fingerprint(terms []any) (string, error) {
+	canon, err := Canonicalize(terms)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(canonicalKey(canon)))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Equivalent reports whether two domains match the same records. Domains
+// with the same canonical form are equivalent; otherwise Implies is tried in
+// both directions. A false result means equivalence could not be shown.
+func Equivalent(a, b []any) (bool, error) {
+	canonA, err := Canonicalize(a)
+	if err != nil {
+		return false, err
+	}
+	canonB, err := Canonicalize(b)
+	if err != nil {
+		return false, err
+	}
+	if canonicalKey(canonA) == canonicalKey(canonB) {
+		return true, nil
+	}
+	forward, err := Implies(canonA, canonB)
+	if err != nil || forward != VerdictTrue {
+		return false, err
+	}
+	backward, err := Implies(canonB, canonA)
+	if err != nil {
+		return false, err
+	}
+	return backward == VerdictTrue, nil
+}
+
+func canonicalNode(n *node) (*node, error) {
+	switch n.op {
+	case "":
+		term, err := canonicalTerm(n.leaf)
+		if err != nil {
+			return nil, err
+		}
+		return newLeaf(term), nil
+	case "!":
+		child, err := canonicalNode(n.children[0])
+		if err != nil {
+			return nil, err
+		}
+		return newNot(child), nil
+	default:
+		byKey := make(map[string]*node, len(n.children))
+		keys := make([]string, 0, len(n.children))
+		for _, child := range newNary(n.op, n.children...).children {
+			canon, err := canonicalNode(child)
+			if err != nil {
+				return nil, err
+			}
+			key := canonicalKey(canon.toDomain())
+			if _, seen := byKey[key]; seen {
+				continue
+			}
+			byKey[key] = canon
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		children := make([]*node, len(keys))
+		for i, key := range keys {
+			children[i] = byKey[key]
+		}
+		return newNary(n.op, children...), nil
+	}
+}
+
+func canonicalTerm(term []any) ([]any, error) {
+	op, _ := term[1].(string)
+	value := term[2]
+	switch op {
+	case "any", "not any":
+		if nested, ok := value.([]any); ok {
+			canon, err := Canonicalize(nested)
+			if err != nil {
+				return nil, err
+			}
+			return []any{term[0], op, canon}, nil
+		}
+	case "in", "not in":
+		values, isList := value.([]any)
+		if !isList {
+			values = []any{value}
+		}
+		seen := make(map[string]bool, len(values))
+		list := make([]any, 0, len(values))
+		for _, v := range values {
+			v = canonicalValue(v)
+			key := canonicalKey(v)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			list = append(list, v)
+		}
+		sort.SliceStable(list, func(i, j int) bool {
+			return canonicalKey(list[i]) < canonicalKey(list[j])
+		})
+		return []any{term[0], op, list}, nil
+	}
+	return []any{term[0], term[1], canonicalValue(value)}, nil
+}
+
+// canonicalValue collapses the numeric types to int and float64.
+func canonicalValue(v any) any {
+	switch t := v.(type) {
+	case int8:
+		return int(t)
+	case int16:
+		return int(t)
+	case int32:
+		return int(t)
+	case int64:
+		return int(t)
+	case uint8:
+		return int(t)
+	case uint16:
+		return int(t)
+	case uint32:
+		return int(t)
+	case float32:
+		return canonicalValue(float64(t))
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < 1<<53 {
+			return int(t)
+		}
+		return t
+	case Term:
+		return canonicalValue([]any(t))
+	case []any:
+		list := make([]any, len(t))
+		for i, item := range t {
+			list[i] = canonicalValue(item)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+// canonicalKey encodes a canonical value as an unambiguous string, used for
+// sorting operands and as the input to Fingerprint.
+func canonicalKey(v any) string {
+	var sb strings.Builder
+	writeCanonical(&sb, v)
+	return sb.String()
+}
+
+func writeCanonical(sb *strings.Builder, v any) {
+	switch t := v.(type) {
+	case nil:
+		sb.WriteString("None")
+	case bool:
+		if t {
+			sb.WriteString("True")
+		} else {
+			sb.WriteString("False")
+		}
+	case string:
+		sb.WriteString(strconv.Quote(t))
+	case int:
+		sb.WriteString(strconv.Itoa(t))
+	case float64:
+		sb.WriteString(strconv.FormatFloat(t, 'g', -1, 64))
+		sb.WriteString("f")
+	case []any:
+		sb.WriteByte('[')
+		for i, item := range t {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			writeCanonical(sb, item)
+		}
+		sb.WriteByte(']')
+	default:
+		fmt.Fprintf(sb, "%T(%v)", v, v)
+	}
+}
@@ -0,0 +1,87 @@
+package odoosearchdomain
+
+import (
+	"reflect"
+	"testing"
+)
+
+var canonicalizePatterns = []struct {
+	domain   string
+	expected []any
+}{
+	{"[]", []any{}},
+	{"[('a','=',1)]", []any{[]any{"a", "=", 1}}},
+	{"[('b','=',2),('a','=',1)]", []any{"&", []any{"a", "=", 1}, []any{"b", "=", 2}}},
+	{"['&',('a','=',1),('b','=',2)]", []any{"&", []any{"a", "=", 1}, []any{"b", "=", 2}}},
+	{"[('a','=',1),('a','=',1.0)]", []any{[]any{"a", "=", 1}}},
+	{"['!','!',('a','=',1)]", []any{[]any{"a", "=", 1}}},
+	{"['!',('a','=',1)]", []any{[]any{"a", "!=", 1}}},
+	{"['!',('a','<',1)]", []any{"!", []any{"a", "<", 1}}},
+	{"[('id','in',[3,1,2,1])]", []any{[]any{"id", "in", []any{1, 2, 3}}}},
+	{"[('id','in',5)]", []any{[]any{"id", "in", []any{5}}}},
+	{
+		"['|',('c','=',3),'&',('b','=',2),('a','=',1)]",
+		[]any{"|", "&", []any{"a", "=", 1}, []any{"b", "=", 2}, []any{"c", "=", 3}},
+	},
+	{
+		"[('line_ids','any',[('qty','>',1),('name','=','x')])]",
+		[]any{[]any{"line_ids", "any", []any{"&", []any{"name", "=", "x"}, []any{"qty", ">", 1}}}},
+	},
+}
+
+func TestCanonicalize(t *testing.T) {
+	for i, pattern := range canonicalizePatterns {
+		terms, err := ParseDomain(pattern.domain)
+		if err != nil {
+			t.Fatalf("[%d] parse %s: %v", i, pattern.domain, err)
+		}
+		got, err := Canonicalize(terms)
+		if err != nil {
+			t.Errorf("[%d] unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(pattern.expected, got) {
+			t.Errorf("[%d] %s\nexpected: %v\n     got: %v", i, pattern.domain, pattern.expected, got)
+		}
+	}
+}
+
+var equivalentPatterns = []struct {
+	a, b     string
+	expected bool
+}{
+	{"[('a','=',1),('b','=',2)]", "[('b','=',2),('a','=',1)]", true},
+	{`[("name","=","x")]`, "[('name','=','x')]", true},
+	{"['|',('a','=',1),('b','=',2)]", "['|',('b','=',2),('a','=',1)]", true},
+	{"[('state','in',['draft','sent'])]", "['|',('state','=','draft'),('state','=','sent')]", true},
+	{"[('a','=',1)]", "[('a','=',2)]", false},
+	{"['|',('a','=',1),('b','=',2)]", "['&',('a','=',1),('b','=',2)]", false},
+	// a negated range does not match unset values
+	{"[('x','=',False)]", "['&',('x','=',False),'!',('x','>=',5)]", false},
+	{"['!',('x','>=',5)]", "[('x','<',5)]", true},
+}
+
+func TestEquivalentAndFingerprint(t *testing.T) {
+	for i, pattern := range equivalentPatterns {
+		a, _ := ParseDomain(pattern.a)
+		b, _ := ParseDomain(pattern.b)
+		got, err := Equivalent(a, b)
+		if err != nil {
+			t.Errorf("[%d] unexpected error %v", i, err)
+		}
+		if got != pattern.expected {
+			t.Errorf("[%d] Equivalent(%s, %s) expected %v, got %v", i, pattern.a, pattern.b, pattern.expected, got)
+		}
+	}
+
+	a, _ := ParseDomain("[('b','=',2),'|',('c','=',3),('a','=',1)]")
+	b, _ := ParseDomain("['&','|',('a','=',1.0),('c','=',3),('b','=',2)]")
+	fa, _ := Fingerprint(a)
+	fb, _ := Fingerprint(b)
+	if fa != fb {
+		t.Errorf("expected identical fingerprints, got %s and %s", fa, fb)
+	}
+	c, _ := ParseDomain("[('b','=',2),'|',('c','=',3),('a','=',2)]")
+	if fc, _ := Fingerprint(c); fc == fa {
+		t.Errorf("expected different fingerprints for different domains")
+	}
+}
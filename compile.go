@@ -0,0 +1,350 @@
+package odoosearchdomain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Predicate is a domain compiled for repeated evaluation. Field paths are
+// split, in-lists are turned into sets and patterns are prepared once, so
+// Match does no parsing or inspection of the domain structure. A Predicate is
+// immutable and safe for concurrent use.
+type Predicate struct {
+	root matcher // nil for the empty domain, which matches everything
+}
+
+// MatchOptions supplies what evaluation needs beyond the record itself.
+type MatchOptions struct {
+	// Hierarchies resolves child_of and parent_of, keyed by the field the
+	// leaf names, e.g. "parent_id" or "id". In an 'any' subdomain the key
+	// is the field as written inside the subdomain.
+	Hierarchies map[string]HierarchyProvider
+
+	// Location is the user's timezone, the tz of Odoo's context. Datetimes
+	// are instants, and naive datetime strings are in UTC as Odoo stores
+	// them; a date compared with a datetime stands for that day in
+	// Location, and date parts of datetimes are read in Location. Nil
+	// means UTC.
+	Location *time.Location
+
+	// Unaccent ignores accents on Latin letters in the like family, as
+	// Odoo does when the database runs with its unaccent option.
+	Unaccent bool
+	// CaseMapping folds case for ilike and =ilike with locale rules, such
+	// as unicode.TurkishCase, instead of Unicode simple case folding.
+	CaseMapping unicode.SpecialCase
+	// FoldEquality applies the same folding to '=' and 'in' on strings:
+	// they become case-insensitive, and accent-insensitive with Unaccent.
+	FoldEquality bool
+}
+
+// Compile validates a domain and compiles it into a Predicate. It returns an
+// error wrapping ErrIncompatibleTypes for values that do not suit their
+// operator, and ErrUnsupportedOperator for operators it cannot evaluate.
+func Compile(domain []any) (*Predicate, error) {
+	return CompileWithOptions(domain, MatchOptions{})
+}
+
+// CompileWithOptions is Compile with evaluation options.
+func CompileWithOptions(domain []any, opts MatchOptions) (*Predicate, error) {
+	tree, err := buildTree(domain)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return &Predicate{}, nil
+	}
+	root, err := compileNode(tree, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Predicate{root: root}, nil
+}
+
+// Match reports whether a record satisfies the predicate, with the
+// semantics described for the package-level Match.
+func (p *Predicate) Match(record map[string]any) (bool, error) {
+	return p.match(record)
+}
+
+func (p *Predicate) match(record any) (bool, error) {
+	if p.root == nil {
+		return true, nil
+	}
+	return p.root.match(record)
+}
+
+// matcher is a compiled domain node. matchNot evaluates the node under a
+// '!': like Odoo, which distributes negations down to the leaves before
+// building its query, it negates each leaf's operator rather than its
+// result, so NOT (x > 5) is x <= 5 and does not match a NULL x.
+type matcher interface {
+	match(record any) (bool, error)
+	matchNot(record any) (bool, error)
+	// explain traces match, or matchNot when negated. A skipped node is
+	// traced without being evaluated.
+	explain(record any, negated, skip bool) (*Trace, error)
+}
+
+type andMatcher struct {
+	children []matcher
+}
+
+func (m *andMatcher) match(record any) (bool, error) {
+	for _, child := range m.children {
+		ok, err := child.match(record)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchNot applies De Morgan's law: NOT (a & b) = !a | !b.
+func (m *andMatcher) matchNot(record any) (bool, error) {
+	for _, child := range m.children {
+		ok, err := child.matchNot(record)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+type orMatcher struct {
+	children []matcher
+}
+
+func (m *orMatcher) match(record any) (bool, error) {
+	for _, child := range m.children {
+		ok, err := child.match(record)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// matchNot applies De Morgan's law: NOT (a | b) = !a & !b.
+func (m *orMatcher) matchNot(record any) (bool, error) {
+	for _, child := range m.children {
+		ok, err := child.matchNot(record)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+type notMatcher struct {
+	child matcher
+}
+
+func (m *notMatcher) match(record any) (bool, error) {
+	return m.child.matchNot(record)
+}
+
+func (m *notMatcher) matchNot(record any) (bool, error) {
+	return m.child.match(record)
+}
+
+// leafMatcher applies a positive comparison to the values a path reaches.
+// Negative operators are compiled to their positive counterpart with negate
+// set: they hold when no value satisfies the positive comparison.
+//
+// Under a '!', a leaf whose operator has a complement in
+// termOperatorNegation flips negate, and a range operator switches to the
+// inverse comparison. Other operators, such as '=like', are negated as SQL
+// NOT, which never holds for an unset value.
+//
+// A leaf on a date part such as 'birthday.month_number' resolves the path
+// without the suffix and compares the extracted part.
+type leafMatcher struct {
+	term        []any
+	field       string
+	path        []string
+	granularity string
+	loc         *time.Location
+	negate      bool
+	test        valueTest
+	inverse     valueTest // range operators only
+	negatable   bool
+}
+
+func (m *leafMatcher) match(record any) (bool, error) {
+	return m.eval(record, m.test, m.negate)
+}
+
+func (m *leafMatcher) matchNot(record any) (bool, error) {
+	switch {
+	case m.inverse != nil:
+		return m.eval(record, m.inverse, m.negate)
+	case m.negatable:
+		return m.eval(record, m.test, !m.negate)
+	}
+	values, err := resolvePath(record, m.path, m.field)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range values {
+		if evalScalar(v) != nil {
+			ok, err := m.evalValues(values, m.test, m.negate)
+			return !ok && err == nil, err
+		}
+	}
+	return false, nil
+}
+
+func (m *leafMatcher) eval(record any, test valueTest, negate bool) (bool, error) {
+	if len(m.path) == 1 {
+		// Fast path for plain fields holding a single value.
+		v, err := lookupField(record, m.path[0], m.field)
+		if err != nil {
+			return false, err
+		}
+		if _, isList := asList(v); !isList {
+			if v, err = m.part(recordID(v)); err != nil {
+				return false, err
+			}
+			ok, err := test.test(m.field, v)
+			return ok != negate && err == nil, err
+		}
+	}
+	values, err := resolvePath(record, m.path, m.field)
+	if err != nil {
+		return false, err
+	}
+	return m.evalValues(values, test, negate)
+}
+
+func (m *leafMatcher) evalValues(values []any, test valueTest, negate bool) (bool, error) {
+	for _, v := range values {
+		v, err := m.part(v)
+		if err != nil {
+			return false, err
+		}
+		ok, err := test.test(m.field, v)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return !negate, nil
+		}
+	}
+	return negate, nil
+}
+
+// part extracts the leaf's date part from a value, if it has a granularity.
+func (m *leafMatcher) part(v any) (any, error) {
+	if m.granularity == "" {
+		return v, nil
+	}
+	return datePart(m.field, m.granularity, v, m.loc)
+}
+
+// anyMatcher matches the records a relational path reaches against a nested
+// predicate.
+type anyMatcher struct {
+	term   []any
+	field  string
+	path   []string
+	negate bool // 'not any'
+	nested *Predicate
+}
+
+func (m *anyMatcher) match(record any) (bool, error) {
+	return m.eval(record, m.negate)
+}
+
+func (m *anyMatcher) matchNot(record any) (bool, error) {
+	return m.eval(record, !m.negate)
+}
+
+func (m *anyMatcher) eval(record any, negate bool) (bool, error) {
+	records, err := resolveRecords(record, m.path, m.field)
+	if err != nil {
+		return false, err
+	}
+	for _, related := range records {
+		ok, err := m.nested.match(related)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return !negate, nil
+		}
+	}
+	return negate, nil
+}
+
+func compileNode(n *node, opts MatchOptions) (matcher, error) {
+	if n.isLeaf() {
+		return compileLeaf(n.leaf, opts)
+	}
+	children := make([]matcher, len(n.children))
+	for i, child := range n.children {
+		compiled, err := compileNode(child, opts)
+		if err != nil {
+			return nil, err
+		}
+		children[i] = compiled
+	}
+	switch n.op {
+	case "!":
+		return &notMatcher{child: children[0]}, nil
+	case "&":
+		return &andMatcher{children: children}, nil
+	default:
+		return &orMatcher{children: children}, nil
+	}
+}
+
+func compileLeaf(leaf []any, opts MatchOptions) (matcher, error) {
+	field, isString := leaf[0].(string)
+	op, _ := leaf[1].(string)
+	if !isString {
+		return nil, fmt.Errorf("%w: field name %v is not a string", ErrIncompatibleTypes, leaf[0])
+	}
+	path := strings.Split(field, ".")
+
+	if op == "any" || op == "not any" {
+		nested, ok := leaf[2].([]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s expects a domain for %s", ErrIncompatibleTypes, op, field)
+		}
+		predicate, err := CompileWithOptions(nested, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &anyMatcher{term: leaf, field: field, path: path, negate: op == "not any", nested: predicate}, nil
+	}
+
+	negate := false
+	if negativeOperators[op] {
+		op, negate = termOperatorNegation[op], true
+	}
+	test, err := compileTest(field, op, leaf[2], opts)
+	if err != nil {
+		return nil, err
+	}
+	m := &leafMatcher{term: leaf, field: field, path: path, loc: opts.Location, negate: negate, test: test}
+	if n := len(path); n > 1 && dateGranularities[path[n-1]] {
+		m.path, m.granularity = path[:n-1], path[n-1]
+	}
+	if inverse, ok := rangeOperatorNegation[op]; ok {
+		m.inverse = orderTest{op: inverse, value: leaf[2], loc: opts.Location}
+	} else {
+		_, m.negatable = termOperatorNegation[op]
+	}
+	return m, nil
+}
+
+// rangeOperatorNegation is the complement Odoo substitutes for a range
+// operator under '!'. Unlike termOperatorNegation it is not exact for NULL
+// values, which satisfy neither side.
+var rangeOperatorNegation = map[string]string{
+	"<": ">=", ">=": "<",
+	">": "<=", "<=": ">",
+}
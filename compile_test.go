@@ -0,0 +1,204 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	domain, _ := ParseDomain("['|',('state','in',['sale','done']),('amount_total','>',100),('order_line','any',[('name','=like','Ch%')])]")
+	p, err := Compile(domain)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	records := []struct {
+		record   map[string]any
+		expected bool
+	}{
+		{map[string]any{"state": "sale", "amount_total": 0, "order_line": []any{map[string]any{"name": "Chair"}}}, true},
+		{map[string]any{"state": "draft", "amount_total": 150, "order_line": []any{map[string]any{"name": "Chair"}}}, true},
+		{map[string]any{"state": "draft", "amount_total": 50, "order_line": []any{map[string]any{"name": "Chair"}}}, false},
+		{map[string]any{"state": "sale", "amount_total": 0, "order_line": []any{map[string]any{"name": "Desk"}}}, false},
+		{map[string]any{"state": "sale", "amount_total": 0, "order_line": []any{}}, false},
+	}
+	for i, tt := range records {
+		got, err := p.Match(tt.record)
+		if err != nil {
+			t.Errorf("[%d]: unexpected error %v", i, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("[%d]: expected %v, got %v", i, tt.expected, got)
+		}
+	}
+
+	empty, _ := Compile([]any{})
+	if ok, err := empty.Match(map[string]any{}); !ok || err != nil {
+		t.Errorf("expected the empty domain to match, got %v, %v", ok, err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		domain []any
+		err    error
+	}{
+		{[]any{[]any{"name", "like", []any{1}}}, ErrIncompatibleTypes},
+		{[]any{[]any{"line_ids", "any", 5}}, ErrIncompatibleTypes},
+		{[]any{[]any{"line_ids", "any", []any{[]any{"name", "=like", true}}}}, ErrIncompatibleTypes},
+		{[]any{[]any{1, "=", 1}}, ErrIncompatibleTypes},
+		{[]any{"|", []any{"a", "=", 1}}, ErrNotEnoughAndOrTerms},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(tt.domain); !errors.Is(err, tt.err) {
+			t.Errorf("%v: expected %v, got %v", tt.domain, tt.err, err)
+		}
+	}
+}
+
+func TestInTest(t *testing.T) {
+	test := newInTest([]any{1, "a", false, "2024-03-15"}, nil, nil)
+	tests := []struct {
+		value    any
+		expected bool
+		err      error
+	}{
+		{1.0, true, nil},
+		{int64(2), false, nil},
+		{"a", true, nil},
+		{"b", false, nil},
+		{nil, true, nil},
+		{"2024-03-15 00:00:00", true, nil},
+		{true, false, ErrIncompatibleTypes},
+	}
+	for _, tt := range tests {
+		got, err := test.test("f", tt.value)
+		if got != tt.expected || !errors.Is(err, tt.err) {
+			t.Errorf("%v: expected %v, %v, got %v, %v", tt.value, tt.expected, tt.err, got, err)
+		}
+	}
+	if ok, err := newInTest([]any{}, nil, nil).test("f", 1); ok || err != nil {
+		t.Errorf("expected an empty list to match nothing, got %v, %v", ok, err)
+	}
+}
+
+func TestPredicateConcurrent(t *testing.T) {
+	domain, _ := ParseDomain("[('id','in',[0,2,4,6,8]),('name','ilike','rec')]")
+	p, err := Compile(domain)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				id := (g + i) % 10
+				got, err := p.Match(map[string]any{"id": id, "name": fmt.Sprintf("Record %d", id)})
+				if err != nil || got != (id%2 == 0) {
+					t.Errorf("id %d: got %v, %v", id, got, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// --- Benchmarks ---
+
+const benchmarkMatchDomain = "[('state','in',['sale','done','cancel']),'|',('amount_total','>',1000),('partner_id.country_id.code','=','BE'),('name','ilike','so0')]"
+
+// interpret is the naive baseline for the benchmarks: a tree walk over the
+// []any domain that splits field paths and prepares each comparison on
+// every call. It handles '&', '|' and plain leaves, which is all
+// benchmarkMatchDomain needs.
+func interpret(domain []any, record map[string]any) (bool, error) {
+	result := true
+	for pos := 0; pos < len(domain); {
+		ok, next, err := interpretAt(domain, pos, record)
+		if err != nil {
+			return false, err
+		}
+		result, pos = result && ok, next
+	}
+	return result, nil
+}
+
+func interpretAt(domain []any, pos int, record map[string]any) (bool, int, error) {
+	switch op := domain[pos]; op {
+	case "&", "|":
+		left, next, err := interpretAt(domain, pos+1, record)
+		if err != nil {
+			return false, 0, err
+		}
+		right, next, err := interpretAt(domain, next, record)
+		if err != nil {
+			return false, 0, err
+		}
+		if op == "&" {
+			return left && right, next, nil
+		}
+		return left || right, next, nil
+	}
+	leaf := domain[pos].([]any)
+	field, op := leaf[0].(string), leaf[1].(string)
+	test, err := compileTest(field, op, leaf[2], MatchOptions{})
+	if err != nil {
+		return false, 0, err
+	}
+	values, err := resolvePath(record, strings.Split(field, "."), field)
+	if err != nil {
+		return false, 0, err
+	}
+	for _, v := range values {
+		ok, err := test.test(field, v)
+		if err != nil || ok {
+			return ok, pos + 1, err
+		}
+	}
+	return false, pos + 1, nil
+}
+
+func TestInterpretBaseline(t *testing.T) {
+	domain, _ := ParseDomain(benchmarkMatchDomain)
+	for _, state := range []string{"sale", "draft"} {
+		record := maps.Clone(matchRecord)
+		record["state"] = state
+		want, err := Match(domain, record)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if got, err := interpret(domain, record); got != want || err != nil {
+			t.Errorf("state %s: expected %v, got %v, %v", state, want, got, err)
+		}
+	}
+}
+
+func BenchmarkMatch_Interpreted(b *testing.B) {
+	domain, _ := ParseDomain(benchmarkMatchDomain)
+	for b.Loop() {
+		interpret(domain, matchRecord)
+	}
+}
+
+// Match compiles the domain on every call; a Predicate compiles it once.
+func BenchmarkMatch_CompileEachCall(b *testing.B) {
+	domain, _ := ParseDomain(benchmarkMatchDomain)
+	for b.Loop() {
+		Match(domain, matchRecord)
+	}
+}
+
+func BenchmarkMatch_Compiled(b *testing.B) {
+	domain, _ := ParseDomain(benchmarkMatchDomain)
+	p, _ := Compile(domain)
+	for b.Loop() {
+		p.Match(matchRecord)
+	}
+}
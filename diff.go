@@ -0,0 +1,402 @@
+package odoosearchdomain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DiffKind classifies a DiffEntry.
+type DiffKind int
+
+const (
+	// DiffAdded is an element present only in the new domain.
+	DiffAdded DiffKind = iota + 1
+	// DiffRemoved is an element present only in the old domain.
+	DiffRemoved
+	// DiffModified is a leaf whose operator or value changed, or a
+	// connector whose operator changed.
+	DiffModified
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffModified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// DiffEntry is one difference between two domains.
+type DiffEntry struct {
+	Kind DiffKind
+	// Path holds child indexes from the root of the expression tree, in the
+	// new domain for added elements and in the old one otherwise. The
+	// implicit top-level AND is the root; inside a nested 'any' domain the
+	// index 2 of the leaf is followed by indexes into the nested tree.
+	Path []int
+	// Where describes the position for humans, e.g. "AND > 2nd OR".
+	Where string
+	// Old and New are the elements as domain fragments: a leaf as
+	// []any{term}, a subtree in prefix notation, or a connector as
+	// []any{"&"}. Old is nil for additions and New for removals.
+	Old, New []any
+}
+
+func (e DiffEntry) String() string {
+	var subject []any
+	switch e.Kind {
+	case DiffAdded:
+		subject = e.New
+	default:
+		subject = e.Old
+	}
+	text := fmt.Sprintf("%s %s", e.Kind, formatDomainInline(subject))
+	if e.Kind == DiffModified {
+		text += " -> " + formatDomainInline(e.New)
+	}
+	if e.Where != "" {
+		text += " under " + e.Where
+	}
+	return text
+}
+
+// DomainDiff is the result of Diff.
+type DomainDiff struct {
+	Entries []DiffEntry
+	lines   []string
+}
+
+// Unified renders both domains as one indented tree, one element per line,
+// with removed lines prefixed by '-', added lines by '+' and unchanged lines
+// by a space.
+func (d *DomainDiff) Unified() string {
+	var sb strings.Builder
+	sb.WriteString("--- a\n+++ b\n")
+	for _, line := range d.lines {
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Diff aligns the expression trees of two domains and reports the leaves
+// and connectors added, removed and modified between them. Identical
+// operands of a connector match wherever they appear, since '&' and '|' are
+// commutative; the others are paired when they are leaves on the same field
+// or connectors of the same kind, and reported as modified or compared
+// recursively.
+// Nested 'any' and 'not any' domains on the same field are compared the
+// same way.
+func Diff(a, b []any) (*DomainDiff, error) {
+	treeA, err := buildTree(a)
+	if err != nil {
+		return nil, err
+	}
+	treeB, err := buildTree(b)
+	if err != nil {
+		return nil, err
+	}
+	d := &differ{}
+	d.compare(asConjunction(treeA), asConjunction(treeB), side{}, side{}, 0, "", "AND")
+	return &DomainDiff{Entries: d.entries, lines: d.lines}, nil
+}
+
+// asConjunction wraps a root that is not an AND so that both roots of a
+// diff line up with the implicit top-level AND.
+func asConjunction(n *node) *node {
+	if n == nil {
+		return &node{op: "&"}
+	}
+	if n.op == "&" {
+		return n
+	}
+	return &node{op: "&", children: []*node{n}}
+}
+
+// side tracks a position in one of the two trees.
+type side struct {
+	path []int
+}
+
+func (s side) child(i int) side {
+	return side{path: appendPath(s.path, i)}
+}
+
+type differ struct {
+	entries []DiffEntry
+	lines   []string
+}
+
+// compare aligns a with b. where describes the enclosing connector and here
+// the connector a and b themselves, when they are connectors.
+func (d *differ) compare(a, b *node, sa, sb side, depth int, where, here string) {
+	switch {
+	case a.isLeaf() && b.isLeaf():
+		d.compareLeaves(a, b, sa, sb, depth, where)
+	case !a.isLeaf() && !b.isLeaf():
+		if a.op == b.op {
+			d.line(' ', depth, quoteConnector(a.op))
+		} else {
+			d.entries = append(d.entries, DiffEntry{
+				Kind: DiffModified, Path: sa.path, Where: where,
+				Old: []any{a.op}, New: []any{b.op},
+			})
+			d.line('-', depth, quoteConnector(a.op))
+			d.line('+', depth, quoteConnector(b.op))
+		}
+		d.compareChildren(a.children, b.children, sa, sb, depth+1, here)
+	default:
+		d.removed(a, sa, depth, where)
+		d.added(b, sb, depth, where)
+	}
+}
+
+func (d *differ) compareLeaves(a, b *node, sa, sb side, depth int, where string) {
+	if nodeKey(a) == nodeKey(b) {
+		d.line(' ', depth, formatTerm(a.leaf))
+		return
+	}
+	nestedA, field, okA := nestedDomain(a.leaf)
+	nestedB, _, okB := nestedDomain(b.leaf)
+	if okA && okB && a.leaf[1] == b.leaf[1] {
+		// Same relation and operator: compare the nested domains.
+		treeA, errA := buildTree(nestedA)
+		treeB, errB := buildTree(nestedB)
+		if errA == nil && errB == nil {
+			inner := fmt.Sprintf("%s > %s(%s)", where, a.leaf[1], field)
+			d.line(' ', depth, fmt.Sprintf("(%s, %s, [", formatLiteral(a.leaf[0]), formatLiteral(a.leaf[1])))
+			d.compare(asConjunction(treeA), asConjunction(treeB), sa.child(2), sb.child(2), depth+1, where, inner)
+			d.line(' ', depth, "])")
+			return
+		}
+	}
+	d.entries = append(d.entries, DiffEntry{
+		Kind: DiffModified, Path: sa.path, Where: where,
+		Old: []any{a.leaf}, New: []any{b.leaf},
+	})
+	d.line('-', depth, formatTerm(a.leaf))
+	d.line('+', depth, formatTerm(b.leaf))
+}
+
+// compareChildren aligns the operands of two connectors. Connectors are
+// commutative, so identical operands are matched regardless of position;
+// the remaining ones are paired when similar, and otherwise removed or
+// added.
+func (d *differ) compareChildren(as, bs []*node, sa, sb side, depth int, where string) {
+	keysB := subtreeKeys(bs)
+	used := make([]bool, len(bs))
+	matched := make([]bool, len(as))
+	for i, key := range subtreeKeys(as) {
+		for j := range bs {
+			if !used[j] && keysB[j] == key {
+				used[j], matched[i] = true, true
+				break
+			}
+		}
+	}
+
+	// Pair leaves on the same field and connectors of the same kind, then
+	// any remaining connectors with each other.
+	pairs := make([]int, len(as))
+	for i, a := range as {
+		pairs[i] = -1
+		if !matched[i] {
+			pairs[i] = pairOperand(a, bs, used, similar)
+		}
+	}
+	for i, a := range as {
+		if !matched[i] && pairs[i] < 0 && !a.isLeaf() {
+			pairs[i] = pairOperand(a, bs, used, func(a, b *node) bool { return !b.isLeaf() })
+		}
+	}
+
+	for i, a := range as {
+		switch j := pairs[i]; {
+		case matched[i]:
+			d.unchanged(a, depth)
+		case j >= 0:
+			d.compare(a, bs[j], sa.child(i), sb.child(j), depth, where, describe(where, bs, j))
+		default:
+			d.removed(a, sa.child(i), depth, where)
+		}
+	}
+	for j, b := range bs {
+		if !used[j] {
+			d.added(b, sb.child(j), depth, where)
+		}
+	}
+}
+
+// pairOperand returns the index of the first unused operand of bs accepted
+// by ok, marking it used, or -1.
+func pairOperand(a *node, bs []*node, used []bool, ok func(a, b *node) bool) int {
+	for j, b := range bs {
+		if !used[j] && ok(a, b) {
+			used[j] = true
+			return j
+		}
+	}
+	return -1
+}
+
+func similar(a, b *node) bool {
+	if a.isLeaf() != b.isLeaf() {
+		return false
+	}
+	if a.isLeaf() {
+		return canonicalKey(a.leaf[0]) == canonicalKey(b.leaf[0])
+	}
+	return a.op == b.op
+}
+
+func subtreeKeys(nodes []*node) []string {
+	keys := make([]string, len(nodes))
+	for i, n := range nodes {
+		keys[i] = nodeKey(n)
+	}
+	return keys
+}
+
+// nodeKey identifies a subtree up to operand order and value list order.
+func nodeKey(n *node) string {
+	if canonical, err := canonicalNode(n); err == nil {
+		n = canonical
+	}
+	return canonicalKey(n.toDomain())
+}
+
+func (d *differ) removed(n *node, s side, depth int, where string) {
+	d.entries = append(d.entries, DiffEntry{Kind: DiffRemoved, Path: s.path, Where: where, Old: n.toDomain()})
+	d.subtree('-', n, depth)
+}
+
+func (d *differ) added(n *node, s side, depth int, where string) {
+	d.entries = append(d.entries, DiffEntry{Kind: DiffAdded, Path: s.path, Where: where, New: n.toDomain()})
+	d.subtree('+', n, depth)
+}
+
+func (d *differ) unchanged(n *node, depth int) {
+	d.subtree(' ', n, depth)
+}
+
+func (d *differ) subtree(marker byte, n *node, depth int) {
+	if n.isLeaf() {
+		d.line(marker, depth, formatTerm(n.leaf))
+		return
+	}
+	d.line(marker, depth, quoteConnector(n.op))
+	for _, child := range n.children {
+		d.subtree(marker, child, depth+1)
+	}
+}
+
+func (d *differ) line(marker byte, depth int, text string) {
+	d.lines = append(d.lines, string(marker)+" "+strings.Repeat("  ", depth)+text)
+}
+
+// describe names the connector at siblings[i] below where, e.g.
+// "AND > 2nd OR". Connectors are named AND, OR and NOT and numbered among
+// the siblings of the same kind.
+func describe(where string, siblings []*node, i int) string {
+	n := siblings[i]
+	if n.isLeaf() {
+		return where
+	}
+	count := 0
+	for _, sibling := range siblings[:i+1] {
+		if sibling.op == n.op {
+			count++
+		}
+	}
+	return fmt.Sprintf("%s > %s %s", where, ordinal(count), connectorNames[n.op])
+}
+
+var connectorNames = map[string]string{"&": "AND", "|": "OR", "!": "NOT"}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// ============================================================
+// Formatting — Odoo (Python literal) syntax
+// ============================================================
+
+func quoteConnector(op string) string {
+	return "'" + op + "'"
+}
+
+func formatTerm(term []any) string {
+	parts := make([]string, len(term))
+	for i, v := range term {
+		parts[i] = formatLiteral(v)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// formatDomainInline renders a domain fragment on one line.
+func formatDomainInline(terms []any) string {
+	parts := make([]string, len(terms))
+	for i, item := range terms {
+		if op, ok := item.(string); ok && isConnector(op) {
+			parts[i] = quoteConnector(op)
+			continue
+		}
+		if term, err := leafTerm(item); err == nil {
+			parts[i] = formatTerm(term)
+			continue
+		}
+		parts[i] = formatLiteral(item)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatLiteral renders a domain value as ParseDomain would accept it.
+func formatLiteral(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if t {
+			return "True"
+		}
+		return "False"
+	case string:
+		r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+		return "'" + r.Replace(t) + "'"
+	case float64:
+		s := strconv.FormatFloat(t, 'f', -1, 64)
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		return s
+	case float32:
+		return formatLiteral(float64(t))
+	case Term:
+		return formatTerm([]any(t))
+	case []any:
+		if len(t) == 3 {
+			if _, isString := t[1].(string); isString && validComparators[t[1].(string)] {
+				return formatTerm(t)
+			}
+		}
+		return "[" + formatDomainInline(t) + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
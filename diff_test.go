@@ -0,0 +1,147 @@
+package odoosearchdomain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected []DiffEntry
+	}{
+		{
+			name: "identical",
+			a:    "[('a','=',1),'|',('b','=',2),('c','=',3)]",
+			b:    "['&',('a','=',1),'|',('b','=',2),('c','=',3)]",
+		},
+		{
+			name: "reordered operands are not a change",
+			a:    "[('a','=',1),('b','in',[1,2])]",
+			b:    "[('b','in',[2,1]),('a','=',1)]",
+		},
+		{
+			name: "leaf modified under the second OR",
+			a:    "['|',('a','=',1),('b','=',2),'|',('c','=',1),('d','=',2)]",
+			b:    "['|',('a','=',1),('b','=',2),'|',('c','=',1),('d','=',3)]",
+			expected: []DiffEntry{
+				{Kind: DiffModified, Path: []int{1, 1}, Where: "AND > 2nd OR", Old: []any{[]any{"d", "=", 2}}, New: []any{[]any{"d", "=", 3}}},
+			},
+		},
+		{
+			name: "added and removed leaves",
+			a:    "[('a','=',1),('b','=',2)]",
+			b:    "[('a','=',1),('c','=',3),('d','=',4)]",
+			expected: []DiffEntry{
+				{Kind: DiffRemoved, Path: []int{1}, Where: "AND", Old: []any{[]any{"b", "=", 2}}},
+				{Kind: DiffAdded, Path: []int{1}, Where: "AND", New: []any{[]any{"c", "=", 3}}},
+				{Kind: DiffAdded, Path: []int{2}, Where: "AND", New: []any{[]any{"d", "=", 4}}},
+			},
+		},
+		{
+			name: "connector modified",
+			a:    "['&',('a','=',1),('b','=',2)]",
+			b:    "['|',('a','=',1),('b','=',2)]",
+			expected: []DiffEntry{
+				{Kind: DiffRemoved, Path: []int{0}, Where: "AND", Old: []any{[]any{"a", "=", 1}}},
+				{Kind: DiffRemoved, Path: []int{1}, Where: "AND", Old: []any{[]any{"b", "=", 2}}},
+				{Kind: DiffAdded, Path: []int{0}, Where: "AND", New: []any{"|", []any{"a", "=", 1}, []any{"b", "=", 2}}},
+			},
+		},
+		{
+			name: "nested connector modified",
+			a:    "[('x','=',0),'!','|',('a','=',1),('b','=',2)]",
+			b:    "[('x','=',0),'!','&',('a','=',1),('b','=',2)]",
+			expected: []DiffEntry{
+				{Kind: DiffModified, Path: []int{1, 0}, Where: "AND > 1st NOT", Old: []any{"|"}, New: []any{"&"}},
+			},
+		},
+		{
+			name: "nested any domain",
+			a:    "[('order_line','any',[('qty','>',1),('price','=',0)])]",
+			b:    "[('order_line','any',[('qty','>',2),('price','=',0)])]",
+			expected: []DiffEntry{
+				{Kind: DiffModified, Path: []int{0, 2, 0}, Where: "AND > any(order_line)", Old: []any{[]any{"qty", ">", 1}}, New: []any{[]any{"qty", ">", 2}}},
+			},
+		},
+		{
+			name: "empty domain",
+			a:    "[]",
+			b:    "[('a','=',1)]",
+			expected: []DiffEntry{
+				{Kind: DiffAdded, Path: []int{0}, Where: "AND", New: []any{[]any{"a", "=", 1}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := ParseDomain(tt.a)
+			if err != nil {
+				t.Fatalf("parse %s: %v", tt.a, err)
+			}
+			b, err := ParseDomain(tt.b)
+			if err != nil {
+				t.Fatalf("parse %s: %v", tt.b, err)
+			}
+			got, err := Diff(a, b)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(tt.expected, got.Entries) {
+				t.Errorf("expected: %v\n     got: %v", tt.expected, got.Entries)
+			}
+		})
+	}
+}
+
+func TestDiffUnified(t *testing.T) {
+	a, _ := ParseDomain("[('state','=','sale'),'|',('a','=',1),('b','=',2),('order_line','any',[('qty','>',1)])]")
+	b, _ := ParseDomain("[('state','=','sale'),'|',('a','=',1),('b','=',3),('order_line','any',[('qty','>',1),('name','ilike','it\\'s')])]")
+	got, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := `--- a
++++ b
+  '&'
+    ('state', '=', 'sale')
+    '|'
+      ('a', '=', 1)
+-     ('b', '=', 2)
++     ('b', '=', 3)
+    ('order_line', 'any', [
+      '&'
+        ('qty', '>', 1)
++       ('name', 'ilike', 'it\'s')
+    ])
+`
+	if got.Unified() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got.Unified())
+	}
+	if s := got.Entries[0].String(); s != "modified ('b', '=', 2) -> ('b', '=', 3) under AND > 1st OR" {
+		t.Errorf("unexpected entry rendering %q", s)
+	}
+}
+
+func TestFormatLiteral(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{nil, "None"},
+		{false, "False"},
+		{true, "True"},
+		{1, "1"},
+		{2.0, "2.0"},
+		{2.5, "2.5"},
+		{`a'b\c`, `'a\'b\\c'`},
+		{[]any{1, "x"}, "[1, 'x']"},
+		{[]any{[]any{"a", "=", 1}}, "[('a', '=', 1)]"},
+	}
+	for _, tt := range tests {
+		if got := formatLiteral(tt.value); got != tt.expected {
+			t.Errorf("%#v: expected %s, got %s", tt.value, tt.expected, got)
+		}
+	}
+}
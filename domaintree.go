@@ -0,0 +1,212 @@
+package odoosearchdomain
+
+import "fmt"
+
+// ============================================================
+// Expression tree — structured view of a flat prefix domain
+// ============================================================
+
+// node is one element of a domain expression tree. Connector nodes carry
+// their operator and operands; leaf nodes carry the original term.
+type node struct {
+	op       string // "&", "|", "!" or "" for a leaf
+	children []*node
+	leaf     []any
+}
+
+// termOperatorNegation maps each operator to its exact complement. Range
+// operators are absent on purpose: an unset x satisfies neither x < 5 nor
+// x >= 5, so the two are not complements and those leaves stay under an
+// explicit '!', which Odoo itself reads as the inverse operator.
+var termOperatorNegation = map[string]string{
+	"=": "!=", "!=": "=",
+	"in": "not in", "not in": "in",
+	"like": "not like", "not like": "like",
+	"ilike": "not ilike", "not ilike": "ilike",
+	"any": "not any", "not any": "any",
+}
+
+func newLeaf(term []any) *node {
+	return &node{leaf: term}
+}
+
+// newNary builds an '&' or '|' node, flattening operands that use the same
+// operator so that associativity does not leave nested copies behind.
+func newNary(op string, children ...*node) *node {
+	n := &node{op: op}
+	for _, child := range children {
+		if child.op == op {
+			n.children = append(n.children, child.children...)
+			continue
+		}
+		n.children = append(n.children, child)
+	}
+	if len(n.children) == 1 {
+		return n.children[0]
+	}
+	return n
+}
+
+func newNot(child *node) *node {
+	return &node{op: "!", children: []*node{child}}
+}
+
+func (n *node) isLeaf() bool {
+	return n.op == ""
+}
+
+// buildTree converts a flat prefix-notation domain into an expression tree.
+// Consecutive top-level expressions are joined by the implicit AND. An empty
+// domain yields a nil tree, which stands for "always true".
+func buildTree(terms []any) (*node, error) {
+	var roots []*node
+	pos := 0
+	for pos < len(terms) {
+		n, count, err := treeAt(terms, pos)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, n)
+		pos += count
+	}
+	switch len(roots) {
+	case 0:
+		return nil, nil
+	case 1:
+		return roots[0], nil
+	default:
+		return newNary("&", roots...), nil
+	}
+}
+
+// treeAt builds the expression rooted at terms[pos] and returns it together
+// with the number of elements it consumed. It mirrors validateAt.
+func treeAt(terms []any, pos int) (*node, int, error) {
+	if pos >= len(terms) {
+		return nil, 0, fmt.Errorf("%w: unexpected end of domain", ErrSyntax)
+	}
+
+	switch terms[pos] {
+	case "&", "|":
+		if pos+1 >= len(terms) {
+			return nil, 0, ErrNotEnoughAndOrTerms
+		}
+		left, count1, err := treeAt(terms, pos+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		if pos+1+count1 >= len(terms) {
+			return nil, 0, ErrNotEnoughAndOrTerms
+		}
+		right, count2, err := treeAt(terms, pos+1+count1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return newNary(terms[pos].(string), left, right), 1 + count1 + count2, nil
+
+	case "!":
+		if pos+1 >= len(terms) {
+			return nil, 0, ErrNotEnoughNotTerms
+		}
+		child, count, err := treeAt(terms, pos+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return newNot(child), 1 + count, nil
+
+	default:
+		term, err := leafTerm(terms[pos])
+		if err != nil {
+			return nil, 0, err
+		}
+		return newLeaf(term), 1, nil
+	}
+}
+
+// leafTerm accepts the term representations produced by ParseDomain and the
+// Domain builder and returns the term as a plain three-element slice.
+func leafTerm(v any) ([]any, error) {
+	var term []any
+	switch t := v.(type) {
+	case []any:
+		term = t
+	case Term:
+		term = []any(t)
+	default:
+		return nil, fmt.Errorf("%w: unexpected domain element %v", ErrSyntax, v)
+	}
+	if len(term) != 3 {
+		return nil, fmt.Errorf("%w: term must have 3 elements, got %d", ErrSyntax, len(term))
+	}
+	return term, nil
+}
+
+// toDomain serializes the tree back into flat prefix notation, emitting
+// explicit connectors everywhere (as Odoo's normalize_domain does).
+func (n *node) toDomain() []any {
+	if n == nil {
+		return []any{}
+	}
+	return n.appendTo([]any{})
+}
+
+func (n *node) appendTo(out []any) []any {
+	switch n.op {
+	case "":
+		return append(out, n.leaf)
+	case "!":
+		out = append(out, "!")
+		return n.children[0].appendTo(out)
+	default:
+		for i := 1; i < len(n.children); i++ {
+			out = append(out, n.op)
+		}
+		for _, child := range n.children {
+			out = child.appendTo(out)
+		}
+		return out
+	}
+}
+
+// negate returns the negation of n with NOT pushed down to the leaves
+// (De Morgan), leaving '!' only in front of leaves without an exact
+// complementary operator.
+func (n *node) negate() *node {
+	switch n.op {
+	case "":
+		if op, ok := n.leaf[1].(string); ok {
+			if neg, ok := termOperatorNegation[op]; ok {
+				return newLeaf([]any{n.leaf[0], neg, n.leaf[2]})
+			}
+		}
+		return newNot(n)
+	case "!":
+		return n.children[0].nnf()
+	default:
+		dual := "|"
+		if n.op == "|" {
+			dual = "&"
+		}
+		children := make([]*node, len(n.children))
+		for i, child := range n.children {
+			children[i] = child.negate()
+		}
+		return newNary(dual, children...)
+	}
+}
+
+// nnf returns n in negation normal form.
+func (n *node) nnf() *node {
+	switch n.op {
+	case "":
+		return n
+	case "!":
+		return n.children[0].negate()
+	default:
+		children := make([]*node, len(n.children))
+		for i, child := range n.children {
+			children[i] = child.nnf()
+		}
+		return newNary(n.op, children...)
+	}
+}
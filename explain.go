@@ -0,0 +1,246 @@
+package odoosearchdomain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Trace explains how a domain evaluated against a record. Its tree mirrors
+// the domain: one node per connector and per leaf, in domain order.
+type Trace struct {
+	// Op is the connector of a connector node: '&', '|' or '!'. It is empty
+	// for a leaf and for the empty domain.
+	Op string
+	// Negated is set on a connector under an odd number of '!'. Like Odoo,
+	// evaluation distributes the negation over its operands, so a negated
+	// '&' holds when any operand holds and a negated '|' when all do.
+	Negated bool
+	// Term is the leaf as written in the domain.
+	Term []any
+	// Operator is the comparison performed. It differs from the leaf's own
+	// operator under a '!': '<=' for a '>' leaf, 'NOT =like' for an
+	// operator without a complement.
+	Operator string
+	// Values are the field values a leaf compared, date parts already
+	// extracted, or the ids of the related records of an 'any' leaf.
+	Values []any
+	// Result is the outcome of the node, after any enclosing negation.
+	Result bool
+	// ShortCircuit is set on a connector whose result was decided before
+	// its last operand.
+	ShortCircuit bool
+	// Skipped is set on operands left unevaluated by a short circuit.
+	Skipped bool
+	// Children are the operands of a connector, or for an 'any' leaf the
+	// traces of its subdomain for each related record tried, in the order
+	// of Values.
+	Children []*Trace
+}
+
+// MatchExplain evaluates a domain like Match and returns the trace of the
+// evaluation; its Result is the outcome of Match.
+func MatchExplain(domain []any, record map[string]any) (*Trace, error) {
+	p, err := Compile(domain)
+	if err != nil {
+		return nil, err
+	}
+	return p.Explain(record)
+}
+
+// Explain evaluates the predicate against a record, a map or a struct as
+// accepted by MatchStruct, and returns the trace of the evaluation.
+func (p *Predicate) Explain(record any) (*Trace, error) {
+	v, err := recordValue(record)
+	if err != nil {
+		return nil, err
+	}
+	return p.explain(v)
+}
+
+func (p *Predicate) explain(record any) (*Trace, error) {
+	if p.root == nil {
+		return &Trace{Result: true}, nil
+	}
+	return p.root.explain(record, false, false)
+}
+
+func (m *andMatcher) explain(record any, negated, skip bool) (*Trace, error) {
+	return explainConnector("&", m.children, record, negated, skip)
+}
+
+func (m *orMatcher) explain(record any, negated, skip bool) (*Trace, error) {
+	return explainConnector("|", m.children, record, negated, skip)
+}
+
+// explainConnector traces the operands of '&' or '|', marking those after
+// the deciding one as skipped.
+func explainConnector(op string, children []matcher, record any, negated, skip bool) (*Trace, error) {
+	t := &Trace{Op: op, Negated: negated, Skipped: skip}
+	// decisive is the operand result that settles the connector: true for
+	// '|', false for '&', and the reverse under a '!'.
+	decisive := (op == "|") != negated
+	t.Result = !decisive && !skip
+	decided := skip
+	for i, child := range children {
+		ct, err := child.explain(record, negated, decided)
+		if err != nil {
+			return nil, err
+		}
+		t.Children = append(t.Children, ct)
+		if !decided && ct.Result == decisive {
+			t.Result, t.ShortCircuit, decided = decisive, i < len(children)-1, true
+		}
+	}
+	return t, nil
+}
+
+func (m *notMatcher) explain(record any, negated, skip bool) (*Trace, error) {
+	child, err := m.child.explain(record, !negated, skip)
+	if err != nil {
+		return nil, err
+	}
+	return &Trace{Op: "!", Negated: negated, Result: child.Result, Skipped: skip, Children: []*Trace{child}}, nil
+}
+
+func (m *leafMatcher) explain(record any, negated, skip bool) (*Trace, error) {
+	t := &Trace{Term: m.term, Operator: m.operator(negated), Skipped: skip}
+	if skip {
+		return t, nil
+	}
+	values, err := resolvePath(record, m.path, m.field)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if v, err = m.part(v); err != nil {
+			return nil, err
+		}
+		t.Values = append(t.Values, v)
+	}
+	if negated {
+		t.Result, err = m.matchNot(record)
+	} else {
+		t.Result, err = m.match(record)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// operator returns the comparison a leaf performs, under a '!' when negated.
+func (m *leafMatcher) operator(negated bool) string {
+	op, _ := m.term[1].(string)
+	if !negated {
+		return op
+	}
+	if neg, ok := termOperatorNegation[op]; ok {
+		return neg
+	}
+	if neg, ok := rangeOperatorNegation[op]; ok {
+		return neg
+	}
+	return "NOT " + op
+}
+
+func (m *anyMatcher) explain(record any, negated, skip bool) (*Trace, error) {
+	op := "any"
+	if m.negate != negated {
+		op = "not any"
+	}
+	t := &Trace{Term: m.term, Operator: op, Skipped: skip}
+	if skip {
+		return t, nil
+	}
+	records, err := resolveRecords(record, m.path, m.field)
+	if err != nil {
+		return nil, err
+	}
+	for _, related := range records {
+		t.Values = append(t.Values, recordID(related))
+	}
+	t.Result = op == "not any"
+	for _, related := range records {
+		child, err := m.nested.explain(related)
+		if err != nil {
+			return nil, err
+		}
+		t.Children = append(t.Children, child)
+		if child.Result {
+			t.Result = op == "any"
+			break
+		}
+	}
+	return t, nil
+}
+
+// ============================================================
+// Rendering
+// ============================================================
+
+// String renders the trace as an indented tree, one node per line, e.g.
+//
+//	AND: false, short-circuited
+//	  ('state', '=', 'sale'): true; state is 'sale'
+//	  ('amount_total', '>', 2000): false; amount_total is 1250.5
+//	  ('name', 'ilike', 'desk'): skipped
+func (t *Trace) String() string {
+	var sb strings.Builder
+	t.write(&sb, 0)
+	return sb.String()
+}
+
+// negatedConnectorNames names how '&' and '|' evaluate under a '!'.
+var negatedConnectorNames = map[string]string{"&": "OR (negated AND)", "|": "AND (negated OR)"}
+
+func (t *Trace) write(sb *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	sb.WriteString(indent)
+	switch {
+	case t.Op != "":
+		name := connectorNames[t.Op]
+		if t.Negated && t.Op != "!" {
+			name = negatedConnectorNames[t.Op]
+		}
+		sb.WriteString(name)
+	case t.Term != nil:
+		sb.WriteString(formatTerm(t.Term))
+		if op, _ := t.Term[1].(string); t.Operator != op {
+			sb.WriteString(" as " + formatLiteral(t.Operator))
+		}
+	default:
+		sb.WriteString("[]")
+	}
+	sb.WriteString(": ")
+	if t.Skipped {
+		sb.WriteString("skipped\n")
+	} else {
+		sb.WriteString(strconv.FormatBool(t.Result))
+		if t.ShortCircuit {
+			sb.WriteString(", short-circuited")
+		}
+		if t.Term != nil {
+			values := make([]string, len(t.Values))
+			for i, v := range t.Values {
+				values[i] = formatLiteral(v)
+			}
+			if len(values) == 0 {
+				values = []string{"nothing"}
+			}
+			field, _ := t.Term[0].(string)
+			sb.WriteString("; " + field + " is " + strings.Join(values, ", "))
+		}
+		sb.WriteByte('\n')
+	}
+	if t.Op == "" && t.Term != nil {
+		// An 'any' leaf: one subdomain trace per related record tried.
+		for i, child := range t.Children {
+			sb.WriteString(indent + "  record " + formatLiteral(t.Values[i]) + ":\n")
+			child.write(sb, depth+2)
+		}
+		return
+	}
+	for _, child := range t.Children {
+		child.write(sb, depth+1)
+	}
+}
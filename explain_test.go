@@ -0,0 +1,84 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMatchExplain(t *testing.T) {
+	domain, _ := ParseDomain("['!','&',('state','=','draft'),('amount_total','>',1000),('order_line','any',[('name','=','Chair')]),('order_line.product_uom_qty','>',4)]")
+	trace, err := MatchExplain(domain, matchRecord)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := `AND: true
+  NOT: true
+    OR (negated AND): true, short-circuited
+      ('state', '=', 'draft') as '!=': true; state is 'sale'
+      ('amount_total', '>', 1000) as '<=': skipped
+  ('order_line', 'any', [('name', '=', 'Chair')]): true; order_line is 11, 12
+    record 11:
+      ('name', '=', 'Chair'): false; name is 'Desk'
+    record 12:
+      ('name', '=', 'Chair'): true; name is 'Chair'
+  ('order_line.product_uom_qty', '>', 4): true; order_line.product_uom_qty is 2, 5
+`
+	if got := trace.String(); got != expected {
+		t.Errorf("unexpected trace:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	leaf := trace.Children[0].Children[0].Children[0]
+	if leaf.Operator != "!=" || !reflect.DeepEqual(leaf.Values, []any{"sale"}) || !leaf.Result {
+		t.Errorf("unexpected leaf trace %+v", leaf)
+	}
+	if skipped := trace.Children[0].Children[0].Children[1]; !skipped.Skipped || skipped.Values != nil {
+		t.Errorf("expected an unevaluated skipped leaf, got %+v", skipped)
+	}
+}
+
+func TestMatchExplainAgreesWithMatch(t *testing.T) {
+	domains := []string{
+		"[]",
+		"[('state','=','draft'),('name','=','x')]",
+		"['|',('state','=','draft'),('amount_total','>=',1250.5)]",
+		"['!','|',('state','=','draft'),('note','=like','x%')]",
+		"['!',('note','=like','x%')]",
+		"[('date_order.month_number','=',3),('tag_ids','not in',[2])]",
+		"[('order_line','not any',[('discount','>',50)])]",
+		"['!',('order_line','any',[('discount','>',5)])]",
+		"[('partner_id.parent_id','not any',[('name','=','x')])]",
+	}
+	for _, text := range domains {
+		domain, _ := ParseDomain(text)
+		expected, err := Match(domain, matchRecord)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", text, err)
+		}
+		trace, err := MatchExplain(domain, matchRecord)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", text, err)
+			continue
+		}
+		if trace.Result != expected {
+			t.Errorf("%s: trace result %v, Match %v\n%s", text, trace.Result, expected, trace)
+		}
+	}
+}
+
+func TestExplainStructsAndErrors(t *testing.T) {
+	p, _ := Compile([]any{[]any{"partner_id.country_id.code", "=", "BE"}, []any{"birthday.month_number", "=", 12}})
+	partner := testPartner{ID: 9, Country: &testCountry{Code: "BE"}}
+	if _, err := p.Explain(&partner); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected ErrFieldNotFound, got %v", err)
+	}
+
+	p, _ = Compile([]any{[]any{"country_id.code", "in", []any{"BE", "NL"}}})
+	trace, err := p.Explain(&partner)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if expected := "('country_id.code', 'in', ['BE', 'NL']): true; country_id.code is 'BE'\n"; trace.String() != expected {
+		t.Errorf("unexpected trace %q", trace.String())
+	}
+}
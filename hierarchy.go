@@ -0,0 +1,197 @@
+package odoosearchdomain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// HierarchyProvider describes the parent relationship of a model, which
+// child_of and parent_of follow. Ids are record ids of that model.
+type HierarchyProvider interface {
+	// ParentID returns the parent of a record; ok is false for a root or an
+	// unknown record.
+	ParentID(id int64) (parent int64, ok bool, err error)
+}
+
+// ParentPathProvider is implemented by providers that can return a record's
+// parent_path, the slash-separated ids of its ancestors and itself, as Odoo
+// stores it for models with _parent_store, e.g. "1/4/9/". The evaluator then
+// reads ancestors from the path instead of walking ParentID.
+type ParentPathProvider interface {
+	HierarchyProvider
+	ParentPath(id int64) (string, error)
+}
+
+// Hierarchy is an in-memory HierarchyProvider.
+type Hierarchy struct {
+	parents map[int64]int64
+}
+
+// NewHierarchy builds a Hierarchy from records, maps or structs as accepted
+// by MatchStruct, holding an "id" and a parent field such as "parent_id".
+// The parent may be unset, an id, an [id, name] pair or a related record.
+// Cycles in the data are tolerated: walks stop at the first repeated id.
+func NewHierarchy[T any](records []T, parentField string) (*Hierarchy, error) {
+	h := &Hierarchy{parents: make(map[int64]int64, len(records))}
+	for _, item := range records {
+		record, err := recordValue(item)
+		if err != nil {
+			return nil, err
+		}
+		idValue, err := lookupField(record, "id", "id")
+		if err != nil {
+			return nil, err
+		}
+		id, ok := hierarchyID(idValue)
+		if !ok {
+			return nil, fmt.Errorf("%w: record id %v is not an id", ErrIncompatibleTypes, idValue)
+		}
+		parentValue, err := lookupField(record, parentField, parentField)
+		if err != nil {
+			return nil, err
+		}
+		if list, isList := asList(parentValue); isList {
+			pair, isPair := many2oneID(list)
+			if !isPair {
+				return nil, fmt.Errorf("%w: %s of record %d holds %v", ErrIncompatibleTypes, parentField, id, parentValue)
+			}
+			parentValue = pair
+		}
+		parentValue = recordID(parentValue)
+		if evalScalar(parentValue) == nil {
+			continue
+		}
+		parent, ok := hierarchyID(parentValue)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s of record %d holds %v", ErrIncompatibleTypes, parentField, id, parentValue)
+		}
+		h.parents[id] = parent
+	}
+	return h, nil
+}
+
+// ParentID implements HierarchyProvider.
+func (h *Hierarchy) ParentID(id int64) (int64, bool, error) {
+	parent, ok := h.parents[id]
+	return parent, ok, nil
+}
+
+// ancestors returns a record and its ancestors, nearest first.
+func ancestors(provider HierarchyProvider, id int64) ([]int64, error) {
+	if paths, ok := provider.(ParentPathProvider); ok {
+		path, err := paths.ParentPath(id)
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			return parseParentPath(path)
+		}
+	}
+	chain := []int64{id}
+	seen := map[int64]bool{id: true}
+	for {
+		parent, ok, err := provider.ParentID(id)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || seen[parent] {
+			return chain, nil
+		}
+		chain = append(chain, parent)
+		seen[parent] = true
+		id = parent
+	}
+}
+
+// parseParentPath reads a parent_path such as "1/4/9/", returning the ids
+// nearest first.
+func parseParentPath(path string) ([]int64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	ids := make([]int64, len(parts))
+	for i, part := range parts {
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed parent_path %q", ErrIncompatibleTypes, path)
+		}
+		ids[len(parts)-1-i] = id
+	}
+	return ids, nil
+}
+
+// hierarchyID converts a record id to an int64.
+func hierarchyID(v any) (int64, bool) {
+	f, ok := evalScalar(v).(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// hierarchyTest evaluates child_of and parent_of. As in Odoo, the value is
+// an id or a list of ids and each record is its own child and parent:
+// child_of holds for the given records and their descendants, parent_of for
+// the given records and their ancestors.
+type hierarchyTest struct {
+	parentOf bool
+	ids      []int64
+	provider HierarchyProvider
+}
+
+func newHierarchyTest(field, op string, value any, provider HierarchyProvider) (valueTest, error) {
+	list, ok := value.([]any)
+	if !ok {
+		list = []any{value}
+	}
+	t := hierarchyTest{parentOf: op == "parent_of", provider: provider}
+	for _, item := range list {
+		if evalScalar(item) == nil {
+			continue
+		}
+		id, ok := hierarchyID(item)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s expects ids for %s, got %v", ErrIncompatibleTypes, op, field, item)
+		}
+		t.ids = append(t.ids, id)
+	}
+	return t, nil
+}
+
+func (t hierarchyTest) test(field string, v any) (bool, error) {
+	if evalScalar(v) == nil {
+		return false, nil
+	}
+	id, ok := hierarchyID(v)
+	if !ok {
+		return false, fmt.Errorf("%w: %s value %v (%T) is not an id", ErrIncompatibleTypes, field, v, v)
+	}
+	if t.parentOf {
+		// v is a parent of one of the ids: it is among their ancestors.
+		for _, child := range t.ids {
+			chain, err := ancestors(t.provider, child)
+			if err != nil {
+				return false, err
+			}
+			for _, ancestor := range chain {
+				if ancestor == id {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+	// v is a child of one of the ids: one of them is among its ancestors.
+	chain, err := ancestors(t.provider, id)
+	if err != nil {
+		return false, err
+	}
+	for _, ancestor := range chain {
+		for _, parent := range t.ids {
+			if ancestor == parent {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
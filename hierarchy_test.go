@@ -0,0 +1,140 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"testing"
+)
+
+// testCategories is a product category tree with a corrupt cycle:
+//
+//	1 All
+//	├── 2 Saleable
+//	│   └── 4 Office Furniture
+//	└── 3 Expenses
+//	20 <-> 21
+var testCategories = []map[string]any{
+	{"id": 1, "parent_id": false},
+	{"id": 2, "parent_id": 1},
+	{"id": 3, "parent_id": []any{1, "All"}},
+	{"id": 4, "parent_id": map[string]any{"id": 2, "name": "Saleable"}},
+	{"id": 20, "parent_id": 21},
+	{"id": 21, "parent_id": []any{20, "Loop"}},
+}
+
+func TestHierarchy(t *testing.T) {
+	h, err := NewHierarchy(testCategories, "parent_id")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	opts := MatchOptions{Hierarchies: map[string]HierarchyProvider{"categ_id": h, "id": h}}
+	tests := []struct {
+		domain   string
+		record   map[string]any
+		expected bool
+	}{
+		{"[('categ_id','child_of',1)]", map[string]any{"categ_id": 4}, true},
+		{"[('categ_id','child_of',[2])]", map[string]any{"categ_id": []any{4, "Office Furniture"}}, true},
+		{"[('categ_id','child_of',2)]", map[string]any{"categ_id": 2}, true},
+		{"[('categ_id','child_of',2)]", map[string]any{"categ_id": 3}, false},
+		{"[('categ_id','child_of',[3,2])]", map[string]any{"categ_id": 4}, true},
+		{"[('categ_id','child_of',[])]", map[string]any{"categ_id": 4}, false},
+		{"[('categ_id','child_of',1)]", map[string]any{"categ_id": false}, false},
+		{"[('categ_id','child_of',4)]", map[string]any{"categ_id": 99}, false},
+		{"[('categ_id','parent_of',4)]", map[string]any{"categ_id": 1}, true},
+		{"[('categ_id','parent_of',4)]", map[string]any{"categ_id": 4}, true},
+		{"[('categ_id','parent_of',[4])]", map[string]any{"categ_id": 3}, false},
+		{"[('categ_id','parent_of',2)]", map[string]any{"categ_id": 4}, false},
+		{"[('id','child_of',2)]", map[string]any{"id": 4}, true},
+		{"['!',('id','child_of',2)]", map[string]any{"id": 3}, true},
+		{"['!',('categ_id','child_of',2)]", map[string]any{"categ_id": false}, false},
+
+		// Cycles terminate.
+		{"[('categ_id','child_of',21)]", map[string]any{"categ_id": 20}, true},
+		{"[('categ_id','child_of',1)]", map[string]any{"categ_id": 20}, false},
+		{"[('categ_id','parent_of',20)]", map[string]any{"categ_id": 1}, false},
+	}
+	for _, tt := range tests {
+		domain, err := ParseDomain(tt.domain)
+		if err != nil {
+			t.Fatalf("parse %s: %v", tt.domain, err)
+		}
+		got, err := MatchWithOptions(domain, tt.record, opts)
+		if err != nil {
+			t.Errorf("%s on %v: unexpected error %v", tt.domain, tt.record, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%s on %v: expected %v, got %v", tt.domain, tt.record, tt.expected, got)
+		}
+	}
+}
+
+func TestHierarchyErrors(t *testing.T) {
+	h, _ := NewHierarchy(testCategories, "parent_id")
+	opts := MatchOptions{Hierarchies: map[string]HierarchyProvider{"categ_id": h}}
+	tests := []struct {
+		domain []any
+		err    error
+	}{
+		{[]any{[]any{"parent_id", "child_of", 1}}, ErrUnsupportedOperator},
+		{[]any{[]any{"categ_id", "child_of", "All"}}, ErrIncompatibleTypes},
+		{[]any{[]any{"categ_id", "parent_of", []any{1.5}}}, ErrIncompatibleTypes},
+	}
+	for _, tt := range tests {
+		if _, err := CompileWithOptions(tt.domain, opts); !errors.Is(err, tt.err) {
+			t.Errorf("%v: expected %v, got %v", tt.domain, tt.err, err)
+		}
+	}
+
+	if _, err := NewHierarchy([]map[string]any{{"id": 1, "parent_id": []any{2, 3}}}, "parent_id"); !errors.Is(err, ErrIncompatibleTypes) {
+		t.Errorf("expected ErrIncompatibleTypes for a list parent, got %v", err)
+	}
+	if _, err := NewHierarchy([]map[string]any{{"id": 1}}, "parent_id"); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected ErrFieldNotFound for a missing parent field, got %v", err)
+	}
+}
+
+// pathHierarchy serves parent_path strings and fails on ParentID, so tests
+// prove the path is used.
+type pathHierarchy map[int64]string
+
+func (pathHierarchy) ParentID(int64) (int64, bool, error) {
+	return 0, false, errors.New("ParentID called")
+}
+
+func (h pathHierarchy) ParentPath(id int64) (string, error) {
+	return h[id], nil
+}
+
+func TestParentPathProvider(t *testing.T) {
+	h := pathHierarchy{1: "1/", 2: "1/2/", 4: "1/2/4/"}
+	opts := MatchOptions{Hierarchies: map[string]HierarchyProvider{"categ_id": h}}
+	for _, tt := range []struct {
+		domain   []any
+		expected bool
+	}{
+		{[]any{[]any{"categ_id", "child_of", 1}}, true},
+		{[]any{[]any{"categ_id", "child_of", 2}}, true},
+		{[]any{[]any{"categ_id", "parent_of", 1}}, false},
+	} {
+		got, err := MatchWithOptions(tt.domain, map[string]any{"categ_id": 4}, opts)
+		if err != nil || got != tt.expected {
+			t.Errorf("%v: expected %v, got %v, %v", tt.domain, tt.expected, got, err)
+		}
+	}
+}
+
+func TestHierarchyStructs(t *testing.T) {
+	partners := []*testPartner{{ID: 1}, {ID: 9, Parent: &testPartner{ID: 1}}}
+	h, err := NewHierarchy(partners, "parent_id")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	p, err := CompileWithOptions([]any{[]any{"id", "child_of", 1}}, MatchOptions{Hierarchies: map[string]HierarchyProvider{"id": h}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if ok, err := p.MatchStruct(partners[1]); !ok || err != nil {
+		t.Errorf("expected a match, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,68 @@
+package odoosearchdomain
+
+// Verdict is a three-valued answer to a question about domains.
+type Verdict int
+
+const (
+	// VerdictUnknown means the analysis could not decide.
+	VerdictUnknown Verdict = iota
+	// VerdictTrue means the property was proven to hold.
+	VerdictTrue
+	// VerdictFalse means a counterexample was found.
+	VerdictFalse
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case VerdictTrue:
+		return "true"
+	case VerdictFalse:
+		return "false"
+	default:
+		return "unknown"
+	}
+}
+
+// Implies reports whether every record matching domain a also matches
+// domain b.
+//
+// VerdictTrue is only returned when the implication is proven, so it is safe
+// to serve a query for a from a cached result for b. VerdictFalse is returned
+// when a concrete assignment of field values matches a but not b, assuming
+// fields are independent and may be unset. Anything the analysis cannot
+// reason about (see CheckSatisfiability) yields VerdictUnknown.
+func Implies(a, b []any) (Verdict, error) {
+	treeA, err := buildTree(a)
+	if err != nil {
+		return VerdictUnknown, err
+	}
+	treeB, err := buildTree(b)
+	if err != nil {
+		return VerdictUnknown, err
+	}
+	if treeB == nil {
+		return VerdictTrue, nil
+	}
+
+	// a implies b exactly when a AND NOT b has no solution. NOT b must be the
+	// exact complement of b, so b's own negations are resolved first.
+	counter := treeB.resolveNegations().negate()
+	if treeA != nil {
+		counter = newNary("&", treeA.resolveNegations().nnf(), counter)
+	}
+	conjunctions, err := distribute(counter, "|", DefaultNormalFormLimit)
+	if err != nil {
+		return VerdictUnknown, err
+	}
+
+	verdict := VerdictTrue
+	for _, conj := range conjunctions {
+		switch conjunctionStatus(conj) {
+		case statusSat:
+			return VerdictFalse, nil
+		case statusUnknown:
+			verdict = VerdictUnknown
+		}
+	}
+	return verdict, nil
+}
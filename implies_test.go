@@ -0,0 +1,61 @@
+package odoosearchdomain
+
+import "testing"
+
+var impliesPatterns = []struct {
+	a, b     string
+	expected Verdict
+}{
+	{"[('state','=','draft')]", "[]", VerdictTrue},
+	{"[]", "[('state','=','draft')]", VerdictFalse},
+	{"[('state','=','draft')]", "[('state','=','draft')]", VerdictTrue},
+	{"[('state','=','draft'),('amount','>',5)]", "[('state','=','draft')]", VerdictTrue},
+	{"[('state','=','draft')]", "[('state','=','draft'),('amount','>',5)]", VerdictFalse},
+	{"[('state','=','draft')]", "[('state','in',['draft','sent'])]", VerdictTrue},
+	{"[('state','in',['draft','sent'])]", "[('state','=','draft')]", VerdictFalse},
+	{"[('state','in',['draft','sent'])]", "[('state','!=','sale')]", VerdictTrue},
+	{"[('amount','>',100)]", "[('amount','>=',50)]", VerdictTrue},
+	{"[('amount','>=',50)]", "[('amount','>',100)]", VerdictFalse},
+	{"[('amount','>',100),('amount','<',200)]", "['|',('amount','<',150),('amount','>=',150)]", VerdictTrue},
+	{"[('date','>=','2024-01-01'),('date','<','2024-02-01')]", "[('date','>=','2024-01-01')]", VerdictTrue},
+	// an unset value matches a but fails the range in b
+	{"[('x','=',False)]", "[('x','<',5)]", VerdictFalse},
+	{"[('x','=',False)]", "['!',('x','>=',5)]", VerdictFalse},
+	{"[('x','=',False)]", "['!',('x','=like','a%')]", VerdictFalse},
+	{"['!',('x','>=',5)]", "[('x','<',5)]", VerdictTrue},
+	{"[('x','<',5)]", "['!',('x','>=',5)]", VerdictTrue},
+	{"['!',('x','>=',5)]", "['|',('x','<',5),('x','=',False)]", VerdictTrue},
+	{"['|',('x','<',5),('x','=',False)]", "['!',('x','>=',5)]", VerdictFalse},
+	// strings are ordered by the database collation, not bytewise
+	{"[('name','>','a')]", "[('name','>','B')]", VerdictUnknown},
+	{"[('name','>=','apple')]", "[('name','>=','Zebra')]", VerdictUnknown},
+	{"[('name','=','b')]", "[('name','>','a')]", VerdictUnknown},
+	{"[('id','=','1')]", "[('id','=',1)]", VerdictUnknown},
+	// boolean structure
+	{"['&',('a','=',1),('b','=',2)]", "['|',('a','=',1),('c','=',3)]", VerdictTrue},
+	{"['|',('a','=',1),('b','=',2)]", "[('a','=',1)]", VerdictFalse},
+	// operators the analysis does not understand
+	{"[('name','ilike','abc')]", "[('name','ilike','ab')]", VerdictUnknown},
+	{"[('name','ilike','abc')]", "[('name','ilike','abc')]", VerdictTrue},
+	{"[('line_ids.qty','>',5)]", "[('line_ids.qty','>',1)]", VerdictUnknown},
+}
+
+func TestImplies(t *testing.T) {
+	for i, pattern := range impliesPatterns {
+		a, err := ParseDomain(pattern.a)
+		if err != nil {
+			t.Fatalf("[%d] parse %s: %v", i, pattern.a, err)
+		}
+		b, err := ParseDomain(pattern.b)
+		if err != nil {
+			t.Fatalf("[%d] parse %s: %v", i, pattern.b, err)
+		}
+		got, err := Implies(a, b)
+		if err != nil {
+			t.Errorf("[%d] unexpected error %v", i, err)
+		}
+		if got != pattern.expected {
+			t.Errorf("[%d] Implies(%s, %s)\nexpected: %v\n     got: %v", i, pattern.a, pattern.b, pattern.expected, got)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidPattern is returned for malformed LIKE patterns.
+var ErrInvalidPattern = errors.New("invalid LIKE pattern")
+
+// LikeOptions controls how a LIKE pattern matches.
+type LikeOptions struct {
+	// CaseInsensitive matches like ILIKE, folding case with Unicode simple
+	// case folding.
+	CaseInsensitive bool
+	// Unaccent removes accents from Latin letters in both the pattern and
+	// the text, as unaccent(text) LIKE unaccent(pattern) does in
+	// PostgreSQL.
+	Unaccent bool
+	// CaseMapping replaces simple case folding with locale rules, such as
+	// unicode.TurkishCase, where I pairs with ı and İ with i.
+	CaseMapping unicode.SpecialCase
+}
+
+// LikePattern is a compiled PostgreSQL LIKE pattern: '%' matches any run of
+// characters, '_' exactly one character, and a backslash makes the next
+// character literal. A LikePattern is safe for concurrent use.
+type LikePattern struct {
+	elems       []likeElem
+	fold        bool
+	unaccent    bool
+	caseMapping unicode.SpecialCase
+}
+
+type likeElemKind uint8
+
+const (
+	likeLiteral likeElemKind = iota
+	likeAnyChar              // _
+	likeAnyRun               // %
+)
+
+type likeElem struct {
+	kind likeElemKind
+	r    rune
+}
+
+// CompileLike compiles a LIKE pattern. A pattern ending with an unescaped
+// backslash returns an error wrapping ErrInvalidPattern, as in PostgreSQL.
+func CompileLike(pattern string, opts LikeOptions) (*LikePattern, error) {
+	p := &LikePattern{fold: opts.CaseInsensitive, unaccent: opts.Unaccent, caseMapping: opts.CaseMapping}
+	if opts.Unaccent {
+		pattern = unaccent(pattern)
+	}
+	for i := 0; i < len(pattern); {
+		r, size := utf8.DecodeRuneInString(pattern[i:])
+		i += size
+		switch r {
+		case '%':
+			// Consecutive '%' are equivalent to one.
+			if n := len(p.elems); n > 0 && p.elems[n-1].kind == likeAnyRun {
+				continue
+			}
+			p.elems = append(p.elems, likeElem{kind: likeAnyRun})
+		case '_':
+			p.elems = append(p.elems, likeElem{kind: likeAnyChar})
+		case '\\':
+			if i >= len(pattern) {
+				return nil, fmt.Errorf("%w: %q ends with the escape character", ErrInvalidPattern, pattern)
+			}
+			r, size = utf8.DecodeRuneInString(pattern[i:])
+			i += size
+			p.elems = append(p.elems, likeElem{kind: likeLiteral, r: r})
+		default:
+			p.elems = append(p.elems, likeElem{kind: likeLiteral, r: r})
+		}
+	}
+	return p, nil
+}
+
+// Match reports whether the whole of s matches the pattern.
+func (p *LikePattern) Match(s string) bool {
+	if p.unaccent {
+		s = unaccent(s)
+	}
+	e, i := 0, 0
+	// Position of the last '%' and of the text it currently absorbs up to.
+	starE, starI := -1, 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if e < len(p.elems) {
+			switch el := p.elems[e]; el.kind {
+			case likeAnyRun:
+				starE, starI = e, i
+				e++
+				continue
+			case likeAnyChar:
+				e++
+				i += size
+				continue
+			default:
+				if el.r == r || (p.fold && p.equalFold(el.r, r)) {
+					e++
+					i += size
+					continue
+				}
+			}
+		}
+		if starE < 0 {
+			return false
+		}
+		// Let the last '%' absorb one more character and retry after it.
+		_, size = utf8.DecodeRuneInString(s[starI:])
+		starI += size
+		e, i = starE+1, starI
+	}
+	for e < len(p.elems) && p.elems[e].kind == likeAnyRun {
+		e++
+	}
+	return e == len(p.elems)
+}
+
+// equalFold compares two runes under the pattern's case folding.
+func (p *LikePattern) equalFold(a, b rune) bool {
+	if p.caseMapping != nil {
+		return foldRune(p.caseMapping, a) == foldRune(p.caseMapping, b)
+	}
+	return equalFold(a, b)
+}
+
+// foldRune folds the case of a rune under a locale's case mapping.
+// Lowering the upper case keeps pairs the locale distinguishes apart, such
+// as Turkish I and i.
+func foldRune(c unicode.SpecialCase, r rune) rune {
+	return c.ToLower(c.ToUpper(r))
+}
+
+// simpleFoldKey returns the smallest rune equal to r under simple case
+// folding, so that equal runes share a key.
+func simpleFoldKey(r rune) rune {
+	key := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		key = min(key, f)
+	}
+	return key
+}
+
+// equalFold reports whether two runes are equal under simple case folding.
+func equalFold(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"testing"
+	"unicode"
+)
+
+func TestCompileLike(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		text     string
+		fold     bool
+		expected bool
+	}{
+		{"", "", false, true},
+		{"", "a", false, false},
+		{"%", "", false, true},
+		{"%", "anything", false, true},
+		{"SO%", "SO007", false, true},
+		{"SO%", "so007", false, false},
+		{"SO%", "so007", true, true},
+		{"S_0%7", "SO007", false, true},
+		{"SO_", "SO007", false, false},
+		{"%a%b%", "xxaxxbxx", false, true},
+		{"%a%b%", "xxbxxaxx", false, false},
+		{"%%%a", "bba", false, true},
+		{"a%a", "aaa", false, true},
+		{"a%a", "a", false, false},
+		{"%ab", "aab", false, true},
+
+		// Escapes make wildcards and backslashes literal.
+		{`100\%`, "100%", false, true},
+		{`100\%`, "1000", false, false},
+		{`a\_b`, "a_b", false, true},
+		{`a\_b`, "axb", false, false},
+		{`a\\b`, `a\b`, false, true},
+		{`\a`, "a", false, true},
+
+		// '_' matches a single character, not a single byte.
+		{"caf_", "café", false, true},
+		{"_", "日", false, true},
+		{"__", "日", false, false},
+		{"%本", "日本", false, true},
+
+		// Unicode simple case folding.
+		{"é", "É", true, true},
+		{"é", "É", false, false},
+		{"σ", "ς", true, true},
+		{"Σ%", "ςοφια", true, true},
+		{"k", "K", true, true}, // Kelvin sign
+		{"straße", "STRASSE", true, false},
+	}
+	for _, tt := range tests {
+		p, err := CompileLike(tt.pattern, LikeOptions{CaseInsensitive: tt.fold})
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", tt.pattern, err)
+		}
+		if got := p.Match(tt.text); got != tt.expected {
+			t.Errorf("%q (fold %v) against %q: expected %v, got %v", tt.pattern, tt.fold, tt.text, tt.expected, got)
+		}
+	}
+}
+
+func TestCompileLikeErrors(t *testing.T) {
+	for _, pattern := range []string{`\`, `abc\`, `%\`} {
+		if _, err := CompileLike(pattern, LikeOptions{}); !errors.Is(err, ErrInvalidPattern) {
+			t.Errorf("%q: expected ErrInvalidPattern, got %v", pattern, err)
+		}
+	}
+	// An escaped backslash at the end is fine.
+	if _, err := CompileLike(`abc\\`, LikeOptions{}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestMatchLikeOperators(t *testing.T) {
+	record := map[string]any{"name": "50% off_sale", "code": "ÉTÉ", "qty": 120}
+	tests := []struct {
+		domain   string
+		expected bool
+	}{
+		// like wraps the value in '%' without escaping it, as Odoo does.
+		{"[('name','like','%')]", true},
+		{"[('name','like','0_ o')]", true},
+		{`[('name','like','0\\% off')]`, true},
+		{`[('name','like','f\\_s')]`, true},
+		{`[('name','like','f\\_x')]`, false},
+		{"[('name','=like','50%')]", true},
+		{"[('name','=like','off%')]", false},
+		{"[('code','ilike','été')]", true},
+		{"[('code','like','été')]", false},
+		{"[('code','=ilike','_t_')]", true},
+		{"[('code','not ilike','t')]", false},
+		{"[('qty','like','2')]", true},
+		{"[('qty','=like','1_')]", false},
+	}
+	for _, tt := range tests {
+		domain, err := ParseDomain(tt.domain)
+		if err != nil {
+			t.Fatalf("parse %s: %v", tt.domain, err)
+		}
+		got, err := Match(domain, record)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.domain, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.domain, tt.expected, got)
+		}
+	}
+
+	if _, err := Compile([]any{[]any{"name", "=like", `50\`}}); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("expected ErrInvalidPattern at compile time, got %v", err)
+	}
+}
+
+func TestCompileLikeOptions(t *testing.T) {
+	turkish := unicode.TurkishCase
+	tests := []struct {
+		pattern  string
+		text     string
+		opts     LikeOptions
+		expected bool
+	}{
+		{"%Jose%", "José Pérez", LikeOptions{}, false},
+		{"%Jose%", "José Pérez", LikeOptions{Unaccent: true}, true},
+		{"%JOSE%", "José Pérez", LikeOptions{Unaccent: true}, false},
+		{"%JOSE%", "José Pérez", LikeOptions{Unaccent: true, CaseInsensitive: true}, true},
+		{"%Pérez", "Jose Perez", LikeOptions{Unaccent: true}, true},
+		{"Stra_e", "Straße", LikeOptions{Unaccent: true}, false},
+		{"Stra__e", "Straße", LikeOptions{Unaccent: true}, true},
+		{"%oe%", "Cœur", LikeOptions{Unaccent: true}, true},
+		{"lodz", "Łódź", LikeOptions{Unaccent: true, CaseInsensitive: true}, true},
+		{`%\%`, "Crème 100%", LikeOptions{Unaccent: true}, true},
+
+		// Turkish case folding keeps dotted and dotless i apart.
+		{"istanbul", "İSTANBUL", LikeOptions{CaseInsensitive: true, CaseMapping: turkish}, true},
+		{"istanbul", "ISTANBUL", LikeOptions{CaseInsensitive: true, CaseMapping: turkish}, false},
+		{"ıstanbul", "ISTANBUL", LikeOptions{CaseInsensitive: true, CaseMapping: turkish}, true},
+		{"istanbul", "ISTANBUL", LikeOptions{CaseInsensitive: true}, true},
+		{"ISTANBUL", "istanbul", LikeOptions{CaseMapping: turkish}, false},
+	}
+	for _, tt := range tests {
+		p, err := CompileLike(tt.pattern, tt.opts)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", tt.pattern, err)
+		}
+		if got := p.Match(tt.text); got != tt.expected {
+			t.Errorf("%q against %q with %+v: expected %v, got %v", tt.pattern, tt.text, tt.opts, tt.expected, got)
+		}
+	}
+}
+
+func TestMatchFolding(t *testing.T) {
+	record := map[string]any{"name": "José Pérez", "city": "İzmir", "code": "ÉTÉ", "date": "2024-03-15"}
+	unaccented := MatchOptions{Unaccent: true}
+	folded := MatchOptions{Unaccent: true, FoldEquality: true}
+	turkish := MatchOptions{CaseMapping: unicode.TurkishCase, FoldEquality: true}
+	tests := []struct {
+		domain   []any
+		opts     MatchOptions
+		expected bool
+	}{
+		{[]any{[]any{"name", "ilike", "jose"}}, MatchOptions{}, false},
+		{[]any{[]any{"name", "ilike", "jose"}}, unaccented, true},
+		{[]any{[]any{"name", "like", "Jose"}}, unaccented, true},
+		{[]any{[]any{"name", "not ilike", "perez"}}, unaccented, false},
+		{[]any{[]any{"name", "=ilike", "jose%"}}, unaccented, true},
+		{[]any{[]any{"name", "=", "jose perez"}}, unaccented, false},
+		{[]any{[]any{"name", "=", "jose perez"}}, folded, true},
+		{[]any{[]any{"name", "!=", "JOSE PEREZ"}}, folded, false},
+		{[]any{[]any{"code", "in", []any{"ete", "hiver"}}}, folded, true},
+		{[]any{[]any{"code", "not in", []any{"ete"}}}, folded, false},
+		{[]any{[]any{"code", "in", []any{"ete"}}}, MatchOptions{FoldEquality: true}, false},
+		{[]any{[]any{"code", "in", []any{"été"}}}, MatchOptions{FoldEquality: true}, true},
+		{[]any{[]any{"date", "=", "2024-03-15 00:00:00"}}, folded, true},
+		{[]any{[]any{"city", "ilike", "izmir"}}, MatchOptions{CaseMapping: unicode.TurkishCase}, true},
+		{[]any{[]any{"city", "ilike", "IZMIR"}}, MatchOptions{CaseMapping: unicode.TurkishCase}, false},
+		{[]any{[]any{"city", "=", "izmir"}}, turkish, true},
+		{[]any{[]any{"city", "in", []any{"IZMIR"}}}, turkish, false},
+	}
+	for _, tt := range tests {
+		got, err := MatchWithOptions(tt.domain, record, tt.opts)
+		if err != nil {
+			t.Errorf("%v with %+v: unexpected error %v", tt.domain, tt.opts, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%v with %+v: expected %v, got %v", tt.domain, tt.opts, tt.expected, got)
+		}
+	}
+	if _, err := MatchWithOptions([]any{[]any{"name", "=", 1}}, record, folded); !errors.Is(err, ErrIncompatibleTypes) {
+		t.Errorf("expected ErrIncompatibleTypes, got %v", err)
+	}
+}
+
+func BenchmarkLikePattern(b *testing.B) {
+	p, _ := CompileLike("%deco%addict%", LikeOptions{CaseInsensitive: true})
+	for b.Loop() {
+		p.Match("Customer Deco Addict, Brussels")
+	}
+}
+
+func BenchmarkLikePatternUnaccent(b *testing.B) {
+	p, _ := CompileLike("%jose%perez%", LikeOptions{CaseInsensitive: true, Unaccent: true})
+	for b.Loop() {
+		p.Match("Customer José Pérez, Sevilla")
+	}
+}
@@ -0,0 +1,526 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for domain evaluation.
+var (
+	ErrFieldNotFound       = errors.New("field not found")
+	ErrIncompatibleTypes   = errors.New("incompatible types")
+	ErrUnsupportedOperator = errors.New("unsupported operator")
+)
+
+// Match reports whether a record, as returned by search_read, satisfies a
+// domain. Fields are looked up by name; dotted paths descend into nested
+// maps, and through lists of maps for x2many relations. Many2one values may
+// be given as an id or as an [id, name] pair, x2many values as a list of
+// ids.
+//
+// As on the server, a leaf on a path through an x2many relation holds when
+// any related record satisfies it, and a negative operator ('!=', 'not in',
+// 'not like', 'not ilike') holds when none satisfies the positive one.
+// False and None both stand for an unset value (SQL NULL), which satisfies
+// '=' False, the negative operators and 'in' lists holding False, and no
+// other comparison. Zero values such as 0 and "" are ordinary values. A '!'
+// is distributed over the leaves as on the server, so it negates operators,
+// not results: NOT (x > 5) is x <= 5, which an unset x does not satisfy.
+//
+// A missing field returns an error wrapping ErrFieldNotFound, and a
+// comparison between values of different kinds one wrapping
+// ErrIncompatibleTypes.
+//
+// Match compiles the domain on every call; use Compile to evaluate the same
+// domain against many records.
+func Match(domain []any, record map[string]any) (bool, error) {
+	p, err := Compile(domain)
+	if err != nil {
+		return false, err
+	}
+	return p.Match(record)
+}
+
+// MatchWithOptions is Match with evaluation options, such as the hierarchy
+// providers child_of and parent_of need.
+func MatchWithOptions(domain []any, record map[string]any, opts MatchOptions) (bool, error) {
+	p, err := CompileWithOptions(domain, opts)
+	if err != nil {
+		return false, err
+	}
+	return p.Match(record)
+}
+
+// ============================================================
+// Field resolution
+// ============================================================
+
+// resolvePath returns the values a dotted path reaches from record: one value
+// per related record at the end of the path, or the ids of an x2many field.
+// An unset relation along the path yields a single nil value.
+func resolvePath(record any, segments []string, path string) ([]any, error) {
+	last := segments[len(segments)-1]
+	containers := []any{record}
+	if len(segments) > 1 {
+		var err error
+		if containers, err = resolveRecords(record, segments[:len(segments)-1], path); err != nil {
+			return nil, err
+		}
+	}
+	var values []any
+	for _, container := range containers {
+		v, err := lookupField(container, last, path)
+		if err != nil {
+			return nil, err
+		}
+		if list, ok := asList(v); ok {
+			if id, ok := many2oneID(list); ok {
+				values = append(values, id)
+				continue
+			}
+			for _, item := range list {
+				if id := recordID(item); id != nil {
+					values = append(values, id)
+				}
+			}
+			continue
+		}
+		values = append(values, recordID(v))
+	}
+	if len(values) == 0 {
+		values = []any{nil}
+	}
+	return values, nil
+}
+
+// resolveRecords follows a dotted path of relations and returns the related
+// records it reaches: nested maps, or the elements of lists of maps.
+func resolveRecords(record any, segments []string, path string) ([]any, error) {
+	current := []any{record}
+	for i, segment := range segments {
+		var next []any
+		for _, container := range current {
+			v, err := lookupField(container, segment, path)
+			if err != nil {
+				return nil, err
+			}
+			if _, isDate := temporalOf(v); isDate && i+1 < len(segments) {
+				return nil, fmt.Errorf("%w: %q in %s is not a date granularity", ErrIncompatibleTypes, segments[i+1], path)
+			}
+			related, err := relatedRecords(v, path)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, related...)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// relatedRecords interprets a relational field value as a set of records:
+// a map or struct, a pointer to one, or a list of them.
+func relatedRecords(v any, path string) ([]any, error) {
+	switch t := v.(type) {
+	case nil, bool:
+		if t == true {
+			break
+		}
+		return nil, nil
+	case map[string]any:
+		return []any{t}, nil
+	}
+	rv := reflect.ValueOf(v)
+	if isRecordValue(rv) {
+		return []any{v}, nil
+	}
+	list, ok := asList(v)
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot traverse %s, its value %v is not a record", ErrIncompatibleTypes, path, v)
+	}
+	if _, ok := many2oneID(list); ok {
+		return nil, fmt.Errorf("%w: cannot traverse %s, its value %v is not a record", ErrIncompatibleTypes, path, v)
+	}
+	records := make([]any, 0, len(list))
+items:
+	for _, item := range list {
+		rv := reflect.ValueOf(item)
+		for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+			if rv.IsNil() {
+				continue items
+			}
+			rv = rv.Elem()
+		}
+		if !isRecordValue(rv) {
+			return nil, fmt.Errorf("%w: %s holds ids, not records", ErrIncompatibleTypes, path)
+		}
+		records = append(records, rv.Interface())
+	}
+	return records, nil
+}
+
+// lookupField returns a field of a record: a map with string keys or a
+// struct.
+func lookupField(container any, name, path string) (any, error) {
+	if record, ok := container.(map[string]any); ok {
+		v, ok := record[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q in %s", ErrFieldNotFound, name, path)
+		}
+		return v, nil
+	}
+	rv := reflect.ValueOf(container)
+	switch {
+	case rv.Kind() == reflect.Struct && isRecordValue(rv):
+		return lookupStructField(rv, name, path)
+	case rv.Kind() == reflect.Map && isRecordValue(rv):
+		fv := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+		if !fv.IsValid() {
+			return nil, fmt.Errorf("%w: %q in %s", ErrFieldNotFound, name, path)
+		}
+		return fv.Interface(), nil
+	}
+	return nil, fmt.Errorf("%w: cannot look up %q of %s in %T", ErrIncompatibleTypes, name, path, container)
+}
+
+// many2oneID returns the id of a many2one [id, name] pair.
+func many2oneID(list []any) (any, bool) {
+	if len(list) != 2 {
+		return nil, false
+	}
+	if _, isName := list[1].(string); !isName {
+		return nil, false
+	}
+	if _, isNumber := evalScalar(list[0]).(float64); !isNumber {
+		return nil, false
+	}
+	return list[0], true
+}
+
+// recordID returns the id of a related record, or the value itself.
+func recordID(v any) any {
+	switch v.(type) {
+	case nil, bool, string, int, float64:
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if isRecordValue(rv) {
+		if id, err := lookupField(rv.Interface(), "id", "id"); err == nil {
+			return id
+		}
+	}
+	return v
+}
+
+// ============================================================
+// Comparisons
+// ============================================================
+
+// valueTest is a compiled positive comparison applied to one record value.
+type valueTest interface {
+	test(field string, v any) (bool, error)
+}
+
+// compileTest prepares the comparison of a positive operator with a value.
+func compileTest(field, op string, value any, opts MatchOptions) (valueTest, error) {
+	if op == "=?" {
+		if evalScalar(value) == nil {
+			return alwaysTest{}, nil
+		}
+		op = "="
+	}
+	var fold *textFolder
+	if opts.FoldEquality {
+		fold = &textFolder{unaccent: opts.Unaccent, caseMapping: opts.CaseMapping}
+	}
+	switch op {
+	case "=":
+		if text, isText := evalScalar(value).(string); isText && fold != nil {
+			if _, isDate := parseDateValue(text); !isDate {
+				return foldedEqualTest{value: value, key: fold.key(text), fold: fold}, nil
+			}
+		}
+		return equalTest{value: value, loc: opts.Location}, nil
+	case "in":
+		return newInTest(value, opts.Location, fold), nil
+	case ">", ">=", "<", "<=":
+		return orderTest{op: op, value: value, loc: opts.Location}, nil
+	case "like", "ilike", "=like", "=ilike":
+		pattern, ok := likeText(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s expects a string pattern for %s, got %v", ErrIncompatibleTypes, op, field, value)
+		}
+		if op == "like" || op == "ilike" {
+			// Odoo wraps the value without escaping it.
+			pattern = "%" + pattern + "%"
+		}
+		compiled, err := CompileLike(pattern, LikeOptions{
+			CaseInsensitive: op == "ilike" || op == "=ilike",
+			Unaccent:        opts.Unaccent,
+			CaseMapping:     opts.CaseMapping,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s on %s: %w", op, field, err)
+		}
+		return likeTest{pattern: compiled}, nil
+	case "child_of", "parent_of":
+		provider, ok := opts.Hierarchies[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q on %s needs a hierarchy provider", ErrUnsupportedOperator, op, field)
+		}
+		return newHierarchyTest(field, op, value, provider)
+	default:
+		return nil, fmt.Errorf("%w: %q on %s", ErrUnsupportedOperator, op, field)
+	}
+}
+
+// alwaysTest is '=?' with an unset value: the leaf is ignored.
+type alwaysTest struct{}
+
+func (alwaysTest) test(string, any) (bool, error) {
+	return true, nil
+}
+
+type equalTest struct {
+	value any
+	loc   *time.Location
+}
+
+func (t equalTest) test(field string, v any) (bool, error) {
+	return valuesEqual(field, v, t.value, t.loc)
+}
+
+// foldedEqualTest is '=' on a string under MatchOptions.FoldEquality.
+type foldedEqualTest struct {
+	value any
+	key   string
+	fold  *textFolder
+}
+
+func (t foldedEqualTest) test(field string, v any) (bool, error) {
+	switch x := evalScalar(v).(type) {
+	case nil:
+		return false, nil
+	case string:
+		return t.fold.key(x) == t.key, nil
+	}
+	return false, incompatible(field, v, t.value)
+}
+
+// inTest looks numbers and plain strings up in sets; other values, such as
+// dates that compare chronologically, are compared one by one. Strings are
+// keyed by their folded form when fold is set.
+type inTest struct {
+	numbers map[float64]bool
+	strings map[string]bool
+	null    bool
+	others  []any
+	loc     *time.Location
+	fold    *textFolder
+}
+
+func newInTest(value any, loc *time.Location, fold *textFolder) inTest {
+	list, ok := value.([]any)
+	if !ok {
+		list = []any{value}
+	}
+	t := inTest{numbers: map[float64]bool{}, strings: map[string]bool{}, loc: loc, fold: fold}
+	for _, item := range list {
+		switch x := evalScalar(item).(type) {
+		case nil:
+			t.null = true
+		case float64:
+			t.numbers[x] = true
+		case string:
+			if _, isDate := parseDateValue(x); !isDate {
+				t.strings[fold.key(x)] = true
+				continue
+			}
+			t.others = append(t.others, item)
+		default:
+			t.others = append(t.others, item)
+		}
+	}
+	return t
+}
+
+func (t inTest) test(field string, v any) (bool, error) {
+	compatible := false
+	switch x := evalScalar(v).(type) {
+	case nil:
+		return t.null, nil
+	case float64:
+		if t.numbers[x] {
+			return true, nil
+		}
+		compatible = len(t.numbers) > 0
+	case string:
+		if t.strings[t.fold.key(x)] {
+			return true, nil
+		}
+		compatible = len(t.strings) > 0
+	}
+	for _, item := range t.others {
+		eq, err := valuesEqual(field, v, item, t.loc)
+		if err == nil {
+			compatible = true
+		}
+		if eq {
+			return true, nil
+		}
+	}
+	if !compatible && len(t.numbers)+len(t.strings)+len(t.others) > 0 {
+		return false, fmt.Errorf("%w: no value of %s's kind in the list for %v (%T)", ErrIncompatibleTypes, field, v, v)
+	}
+	return false, nil
+}
+
+type orderTest struct {
+	op    string
+	value any
+	loc   *time.Location
+}
+
+func (t orderTest) test(field string, v any) (bool, error) {
+	// Like Odoo, a date bound on a datetime includes the whole day for '>'
+	// and '<=': x > '2024-03-15' means after that day.
+	c, ok, err := orderValues(field, v, t.value, t.loc, t.op == ">" || t.op == "<=")
+	if err != nil || !ok {
+		return false, err
+	}
+	switch t.op {
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	case "<":
+		return c < 0, nil
+	default:
+		return c <= 0, nil
+	}
+}
+
+// likeTest applies the like family of operators with PostgreSQL LIKE
+// semantics. '=like' and '=ilike' use the value as the pattern; 'like' and
+// 'ilike' wrap it in '%', so its own wildcards stay active.
+type likeTest struct {
+	pattern *LikePattern
+}
+
+func (t likeTest) test(field string, v any) (bool, error) {
+	if evalScalar(v) == nil {
+		return false, nil
+	}
+	text, ok := likeText(v)
+	if !ok {
+		return false, fmt.Errorf("%w: cannot match %s value %v (%T) against a pattern", ErrIncompatibleTypes, field, v, v)
+	}
+	return t.pattern.Match(text), nil
+}
+
+// evalScalar normalizes a value for comparison: numbers become float64 and
+// False becomes nil, like None. Other values are returned unchanged.
+func evalScalar(v any) any {
+	switch t := v.(type) {
+	case nil, string, float64, time.Time:
+		return v
+	case bool:
+		if !t {
+			return nil
+		}
+		return true
+	case int:
+		return float64(t)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return evalScalar(rv.Bool())
+	}
+	return v
+}
+
+// valuesEqual reports whether a record value a equals a domain value b.
+func valuesEqual(field string, a, b any, loc *time.Location) (bool, error) {
+	x, y := evalScalar(a), evalScalar(b)
+	if x == nil || y == nil {
+		return x == nil && y == nil, nil
+	}
+	if x == true || y == true {
+		if _, isBool := x.(bool); !isBool {
+			return false, incompatible(field, a, b)
+		}
+		if _, isBool := y.(bool); !isBool {
+			return false, incompatible(field, a, b)
+		}
+		return true, nil
+	}
+	c, ok, err := orderValues(field, a, b, loc, false)
+	return ok && c == 0, err
+}
+
+// orderValues compares a record value a with a domain value b of the same
+// kind; ok is false when either is unset, since NULL compares to nothing.
+// Dates and datetimes compare as described for compareTemporal.
+func orderValues(field string, a, b any, loc *time.Location, dayEnd bool) (c int, ok bool, err error) {
+	x, y := evalScalar(a), evalScalar(b)
+	if x == nil || y == nil {
+		return 0, false, nil
+	}
+	switch tx := x.(type) {
+	case float64:
+		if ty, isNumber := y.(float64); isNumber {
+			switch {
+			case tx < ty:
+				return -1, true, nil
+			case tx > ty:
+				return 1, true, nil
+			}
+			return 0, true, nil
+		}
+	}
+	if ta, isTime := temporalOf(x); isTime {
+		if tb, isTime := temporalOf(y); isTime {
+			return compareTemporal(ta, tb, loc, dayEnd), true, nil
+		}
+	}
+	if sx, isString := x.(string); isString {
+		if sy, isString := y.(string); isString {
+			return strings.Compare(sx, sy), true, nil
+		}
+	}
+	return 0, false, incompatible(field, a, b)
+}
+
+func incompatible(field string, a, b any) error {
+	return fmt.Errorf("%w: cannot compare %s value %v (%T) with %v (%T)", ErrIncompatibleTypes, field, a, a, b, b)
+}
+
+// likeText renders a value as the text a LIKE comparison sees.
+func likeText(v any) (string, bool) {
+	switch t := evalScalar(v).(type) {
+	case string:
+		return t, true
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), true
+	}
+	return "", false
+}
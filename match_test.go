@@ -0,0 +1,327 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// matchRecord is a sale order as returned by search_read, with some
+// relations expanded.
+var matchRecord = map[string]any{
+	"id":           7,
+	"name":         "SO007",
+	"state":        "sale",
+	"amount_total": 1250.5,
+	"active":       true,
+	"note":         false,
+	"date_order":   "2024-03-15 10:30:00",
+	"user_id":      []any{2, "Mitchell Admin"},
+	"tag_ids":      []any{1, 3},
+	"empty_ids":    []any{},
+	"partner_id": map[string]any{
+		"id":         9,
+		"name":       "Deco Addict",
+		"country_id": map[string]any{"id": 21, "code": "BE"},
+		"parent_id":  false,
+	},
+	"order_line": []any{
+		map[string]any{"id": 11, "product_uom_qty": 2, "name": "Desk", "discount": 0},
+		map[string]any{"id": 12, "product_uom_qty": 5, "name": "Chair", "discount": 10.0},
+	},
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		domain   string
+		expected bool
+	}{
+		{"[]", true},
+		{"[('state','=','sale')]", true},
+		{"[('state','!=','sale')]", false},
+		{"[('id','=',7.0)]", true},
+		{"[('amount_total','>',1000),('amount_total','<=',1250.5)]", true},
+		{"[('amount_total','<',1000)]", false},
+		{"['|',('state','=','draft'),('amount_total','>=',1250.5)]", true},
+		{"['!',('state','=','sale')]", false},
+		{"[('state','in',['sale','done'])]", true},
+		{"[('state','not in',['sale','done'])]", false},
+		{"[('active','=',True)]", true},
+		{"[('active','!=',False)]", true},
+		{"[('note','=',False)]", true},
+		{"[('note','=',None)]", true},
+		{"[('note','!=','x')]", true},
+		{"[('note','>','a')]", false},
+		{"[('state','=?',False)]", true},
+		{"[('state','=?','draft')]", false},
+		{"[('date_order','>=','2024-03-15')]", true},
+		{"[('date_order','<','2024-03-15 10:00:00')]", false},
+
+		// like family
+		{"[('name','like','O00')]", true},
+		{"[('name','like','so')]", false},
+		{"[('name','ilike','so')]", true},
+		{"[('name','not ilike','so')]", false},
+		{"[('name','=like','SO%')]", true},
+		{"[('name','=like','SO_')]", false},
+		{"[('name','=like','S_0%7')]", true},
+		{"[('name','=ilike','so%')]", true},
+		{"[('note','not like','x')]", true},
+
+		// relations
+		{"[('user_id','=',2)]", true},
+		{"[('user_id','in',[1,2])]", true},
+		{"[('tag_ids','in',[3,4])]", true},
+		{"[('tag_ids','=',1)]", true},
+		{"[('tag_ids','not in',[3])]", false},
+		{"[('tag_ids','!=',2)]", true},
+		{"[('empty_ids','=',False)]", true},
+		{"[('empty_ids','!=',False)]", false},
+		{"[('partner_id','=',9)]", true},
+		{"[('partner_id.country_id.code','=','BE')]", true},
+		{"[('partner_id.parent_id.name','=','x')]", false},
+		{"[('partner_id.parent_id.name','=',False)]", true},
+		{"[('order_line.name','=','Chair')]", true},
+		{"[('order_line.name','!=','Chair')]", false},
+		{"[('order_line.product_uom_qty','>',4)]", true},
+		{"[('order_line','in',[12])]", true},
+		{"[('order_line','any',[('name','=','Desk'),('product_uom_qty','>',4)])]", false},
+		{"[('order_line','any',[('name','=','Chair'),('product_uom_qty','>',4)])]", true},
+		{"[('order_line','not any',[('discount','>',50)])]", true},
+		{"[('partner_id','any',[('country_id.code','=','BE')])]", true},
+		{"[('partner_id.parent_id','any',[])]", false},
+		{"[('partner_id.parent_id','not any',[('name','=','x')])]", true},
+	}
+	for _, tt := range tests {
+		domain, err := ParseDomain(tt.domain)
+		if err != nil {
+			t.Fatalf("parse %s: %v", tt.domain, err)
+		}
+		got, err := Match(domain, matchRecord)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.domain, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.domain, tt.expected, got)
+		}
+	}
+}
+
+func TestMatchErrors(t *testing.T) {
+	tests := []struct {
+		domain string
+		err    error
+	}{
+		{"[('missing','=',1)]", ErrFieldNotFound},
+		{"[('partner_id.missing','=',1)]", ErrFieldNotFound},
+		{"[('state','=',1)]", ErrIncompatibleTypes},
+		{"[('amount_total','>','a')]", ErrIncompatibleTypes},
+		{"[('active','=',1)]", ErrIncompatibleTypes},
+		{"[('user_id.name','=','x')]", ErrIncompatibleTypes},
+		{"[('tag_ids','any',[('name','=','x')])]", ErrIncompatibleTypes},
+		{"[('state','any','x')]", ErrIncompatibleTypes},
+		{"[('partner_id','child_of',[9])]", ErrUnsupportedOperator},
+		{"['|',('state','=','draft'),('missing','=',1)]", ErrFieldNotFound},
+	}
+	for _, tt := range tests {
+		domain, err := ParseDomain(tt.domain)
+		if err != nil {
+			t.Fatalf("parse %s: %v", tt.domain, err)
+		}
+		if _, err := Match(domain, matchRecord); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.domain, tt.err, err)
+		}
+	}
+
+	// Evaluation short-circuits like the connectors do.
+	domain, _ := ParseDomain("['|',('state','=','sale'),('missing','=',1)]")
+	if ok, err := Match(domain, matchRecord); !ok || err != nil {
+		t.Errorf("expected a short-circuited match, got %v, %v", ok, err)
+	}
+}
+
+func TestMatchTimeValues(t *testing.T) {
+	record := map[string]any{"date": time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), "qty": int64(3), "count": uint8(2)}
+	domain := []any{[]any{"date", ">", "2024-03-01"}, []any{"qty", "=", 3}, []any{"count", "<", 2.5}}
+	if ok, err := Match(domain, record); !ok || err != nil {
+		t.Errorf("expected a match, got %v, %v", ok, err)
+	}
+}
+
+// TestMatchNullSemantics is the truth table of each operator on unset and
+// zero values. Each expected string has one letter per column: T for a
+// match, F for none. In Odoo False stands for NULL; zero values such as 0
+// and "" are ordinary values. A '!' is distributed down to the leaves as
+// Odoo does, so it negates the operator, not the result: NOT (x > 'a') is
+// x <= 'a', which NULL does not satisfy either, and operators without a
+// complement become SQL NOT, which is never true for NULL.
+func TestMatchNullSemantics(t *testing.T) {
+	tables := []struct {
+		columns []any
+		rows    []struct{ domain, expected string }
+	}{
+		{
+			columns: []any{nil, false, "", "foo"},
+			rows: []struct{ domain, expected string }{
+				{"[('x','=',False)]", "TTFF"},
+				{"[('x','=',None)]", "TTFF"},
+				{"[('x','!=',False)]", "FFTT"},
+				{"[('x','=','foo')]", "FFFT"},
+				{"[('x','!=','foo')]", "TTTF"},
+				{"[('x','=','')]", "FFTF"},
+				{"[('x','in',['foo'])]", "FFFT"},
+				{"[('x','in',['foo',False])]", "TTFT"},
+				{"[('x','not in',['foo'])]", "TTTF"},
+				{"[('x','not in',['foo',False])]", "FFTF"},
+				{"[('x','in',[])]", "FFFF"},
+				{"[('x','not in',[])]", "TTTT"},
+				{"[('x','=?',False)]", "TTTT"},
+				{"[('x','=?',None)]", "TTTT"},
+				{"[('x','=?','foo')]", "FFFT"},
+				{"[('x','like','o')]", "FFFT"},
+				{"[('x','like','')]", "FFTT"},
+				{"[('x','not like','o')]", "TTTF"},
+				{"[('x','=like','%')]", "FFTT"},
+				{"[('x','>','a')]", "FFFT"},
+				{"[('x','<=','a')]", "FFTF"},
+				{"['!',('x','>','a')]", "FFTF"},
+				{"['!',('x','=','foo')]", "TTTF"},
+				{"['!',('x','in',['foo',False])]", "FFTF"},
+				{"['!',('x','not like','o')]", "FFFT"},
+				{"['!',('x','=like','%')]", "FFFF"},
+				{"['!',('x','=like','x%')]", "FFTT"},
+				{"['!',('x','=?',False)]", "FFFF"},
+				{"['!',('x','=?','foo')]", "FFTF"},
+				{"['!','!',('x','>','a')]", "FFFT"},
+				{"['!','|',('x','=',False),('x','>','a')]", "FFTF"},
+			},
+		},
+		{
+			columns: []any{nil, false, 0, 5},
+			rows: []struct{ domain, expected string }{
+				{"[('x','=',False)]", "TTFF"},
+				{"[('x','!=',False)]", "FFTT"},
+				{"[('x','=',0)]", "FFTF"},
+				{"[('x','!=',0)]", "TTFT"},
+				{"[('x','>',0)]", "FFFT"},
+				{"[('x','<=',0)]", "FFTF"},
+				{"[('x','>',False)]", "FFFF"},
+				{"['!',('x','>',0)]", "FFTF"},
+				{"['!',('x','<',5)]", "FFFT"},
+				{"['!',('x','>',False)]", "FFFF"},
+				{"['!','&',('x','>=',0),('x','<',5)]", "FFFT"},
+				{"[('x','=?',0)]", "FFTF"},
+				{"[('x','in',[0,False])]", "TTTF"},
+				{"[('x','not in',[0])]", "TTFT"},
+				{"[('x','not in',[0,False])]", "FFFT"},
+				{"[('x','like','0')]", "FFTF"},
+			},
+		},
+		{
+			columns: []any{nil, false, true},
+			rows: []struct{ domain, expected string }{
+				{"[('x','=',False)]", "TTF"},
+				{"[('x','!=',False)]", "FFT"},
+				{"[('x','=',True)]", "FFT"},
+				{"[('x','!=',True)]", "TTF"},
+				{"['!',('x','=',True)]", "TTF"},
+				{"[('x','in',[True,False])]", "TTT"},
+			},
+		},
+	}
+	for _, table := range tables {
+		for _, row := range table.rows {
+			domain, err := ParseDomain(row.domain)
+			if err != nil {
+				t.Fatalf("parse %s: %v", row.domain, err)
+			}
+			for i, value := range table.columns {
+				got, err := Match(domain, map[string]any{"x": value})
+				if err != nil {
+					t.Errorf("%s on %#v: unexpected error %v", row.domain, value, err)
+					continue
+				}
+				if expected := row.expected[i] == 'T'; got != expected {
+					t.Errorf("%s on %#v: expected %v, got %v", row.domain, value, expected, got)
+				}
+			}
+			// A missing key is not an unset value.
+			if _, err := Match(domain, map[string]any{"y": 1}); !errors.Is(err, ErrFieldNotFound) {
+				t.Errorf("%s on a missing key: expected ErrFieldNotFound, got %v", row.domain, err)
+			}
+		}
+	}
+}
+
+func TestMatchDateGranularity(t *testing.T) {
+	record := map[string]any{
+		"date_order":  "2024-03-15 10:30:45",
+		"birthday":    "1990-12-25",
+		"create_date": time.Date(2021, 1, 3, 23, 5, 0, 0, time.FixedZone("UTC+2", 2*3600)),
+		"deadline":    false,
+		"name":        "SO007",
+		"order_line":  []any{map[string]any{"date": "2024-02-29"}, map[string]any{"date": "2024-07-01"}},
+	}
+	tests := []struct {
+		domain   string
+		expected bool
+	}{
+		{"[('date_order.year_number','=',2024)]", true},
+		{"[('date_order.quarter_number','=',1)]", true},
+		{"[('date_order.month_number','in',[3,4])]", true},
+		{"[('date_order.iso_week_number','=',11)]", true},
+		{"[('date_order.day_of_week','=',5)]", true},
+		{"[('date_order.day_of_month','=',15)]", true},
+		{"[('date_order.day_of_year','=',75)]", true},
+		{"[('date_order.hour_number','=',10)]", true},
+		{"[('date_order.minute_number','>=',30)]", true},
+		{"[('date_order.second_number','=',45)]", true},
+		{"[('birthday.month_number','=',12),('birthday.day_of_month','=',25)]", true},
+		{"[('birthday.hour_number','=',0)]", true},
+		{"[('birthday.quarter_number','!=',4)]", false},
+		{"['!',('birthday.month_number','>',6)]", false},
+
+		// time.Time values are read in UTC: 2021-01-03 21:05, a Sunday in
+		// ISO week 53 of 2020.
+		{"[('create_date.day_of_week','=',0)]", true},
+		{"[('create_date.iso_week_number','=',53)]", true},
+		{"[('create_date.year_number','=',2021)]", true},
+		{"[('create_date.hour_number','=',21)]", true},
+
+		// Unset dates have no parts.
+		{"[('deadline.month_number','=',False)]", true},
+		{"[('deadline.month_number','>',0)]", false},
+		{"[('deadline.month_number','!=',1)]", true},
+
+		// Through x2many relations, any related date may match.
+		{"[('order_line.date.month_number','=',7)]", true},
+		{"[('order_line.date.day_of_month','=',29)]", true},
+		{"[('order_line.date.quarter_number','=',2)]", false},
+	}
+	for _, tt := range tests {
+		domain, err := ParseDomain(tt.domain)
+		if err != nil {
+			t.Fatalf("parse %s: %v", tt.domain, err)
+		}
+		got, err := Match(domain, record)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.domain, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.domain, tt.expected, got)
+		}
+	}
+
+	for _, domain := range []string{
+		"[('date_order.week_number','=',11)]",
+		"[('birthday.day_of_quarter','=',1)]",
+		"[('name.month_number','=',1)]",
+	} {
+		terms, _ := ParseDomain(domain)
+		if _, err := Match(terms, record); !errors.Is(err, ErrIncompatibleTypes) {
+			t.Errorf("%s: expected ErrIncompatibleTypes, got %v", domain, err)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package odoosearchdomain
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MigrationRule describes how leaves on one field are migrated. A rule
+// matches a leaf whose full field path (including the scope of enclosing
+// 'any' leaves) equals Field and whose operator and value pass the optional
+// Operator and Values filters. Exactly one action applies, in this order of
+// precedence: Unsupported, Replace, then Rename and/or NewOperator.
+type MigrationRule struct {
+	// Field is the field path from the domain's model, e.g. "customer".
+	Field string
+	// Operator restricts the rule to leaves using this operator.
+	Operator string
+	// Values restricts the rule to leaves whose value is one of these, or
+	// for 'in' and 'not in' whose list holds one of these. False and None
+	// are interchangeable, as are ints and floats.
+	Values []any
+
+	// Unsupported marks matching leaves as impossible to migrate; the leaf
+	// is kept and reported with this reason.
+	Unsupported string
+	// Replace is a domain that replaces the matching leaf, written relative
+	// to the leaf's scope.
+	Replace []any
+	// Rename gives the field a new name, as a mapping value of RenameFields.
+	// A rename rule without Operator or Values also renames the field in
+	// leaves matched by its other rules that do not rename it themselves,
+	// and where it appears as a prefix of a longer path.
+	Rename string
+	// NewOperator replaces the leaf's operator.
+	NewOperator string
+}
+
+// MigrationStep is the set of rules taking stored domains from one Odoo
+// version to the next.
+type MigrationStep struct {
+	From, To string
+	Rules    []MigrationRule
+}
+
+func (s MigrationStep) name() string {
+	return s.From + " -> " + s.To
+}
+
+// MigrationChange records a leaf rewritten by Migrate.
+type MigrationChange struct {
+	Step  string
+	Path  []int // location of the leaf in the domain the step was applied to
+	Scope string
+	// Before is the original leaf, After the domain that replaced it.
+	Before []any
+	After  []any
+}
+
+// MigrationIssue records a leaf Migrate could not migrate.
+type MigrationIssue struct {
+	Step   string
+	Path   []int // location of the leaf in the domain the step was applied to
+	Scope  string
+	Leaf   []any
+	Reason string
+}
+
+// MigrationReport lists what Migrate changed and what it could not migrate.
+type MigrationReport struct {
+	Changes []MigrationChange
+	Issues  []MigrationIssue
+}
+
+// Odoo13PartnerRanks migrates res.partner domains to Odoo 13.0, where the
+// boolean customer and supplier fields were replaced by the customer_rank
+// and supplier_rank counters.
+var Odoo13PartnerRanks = MigrationStep{
+	From: "12.0",
+	To:   "13.0",
+	Rules: append(
+		rankRules("customer", "customer_rank"),
+		rankRules("supplier", "supplier_rank")...,
+	),
+}
+
+// Odoo17SaleOrderLocked migrates sale.order domains to Odoo 17.0, where the
+// 'done' state was dropped: a locked order is a 'sale' order with the locked
+// flag set.
+var Odoo17SaleOrderLocked = MigrationStep{
+	From: "16.0",
+	To:   "17.0",
+	Rules: []MigrationRule{
+		{Field: "state", Operator: "=", Values: []any{"sale"}, Replace: []any{"&", []any{"state", "=", "sale"}, []any{"locked", "=", false}}},
+		{Field: "state", Operator: "=", Values: []any{"done"}, Replace: []any{"&", []any{"state", "=", "sale"}, []any{"locked", "=", true}}},
+		{Field: "state", Operator: "!=", Values: []any{"sale"}, Replace: []any{"|", []any{"state", "!=", "sale"}, []any{"locked", "=", true}}},
+		{Field: "state", Operator: "!=", Values: []any{"done"}, Replace: []any{"|", []any{"state", "!=", "sale"}, []any{"locked", "=", false}}},
+		{Field: "state", Operator: "in", Values: []any{"sale", "done"}, Unsupported: "'sale' and 'done' became the locked flag; split the list into '=' leaves"},
+		{Field: "state", Operator: "not in", Values: []any{"sale", "done"}, Unsupported: "'sale' and 'done' became the locked flag; split the list into '!=' leaves"},
+		{Field: "state"},
+	},
+}
+
+// Odoo18PartnerMobile migrates res.partner domains to Odoo 18.0, where the
+// mobile field was removed and numbers are kept in phone. Searches on a
+// number carry over; tests for an unset mobile cannot, since phone may hold
+// another number.
+var Odoo18PartnerMobile = MigrationStep{
+	From: "17.0",
+	To:   "18.0",
+	Rules: []MigrationRule{
+		{Field: "mobile", Values: []any{false}, Unsupported: "mobile was merged into phone; partners without a mobile number cannot be told apart"},
+		{Field: "mobile", Rename: "phone"},
+	},
+}
+
+func rankRules(field, rank string) []MigrationRule {
+	isSet := []any{[]any{rank, ">", 0}}
+	isUnset := []any{[]any{rank, "=", 0}}
+	return []MigrationRule{
+		{Field: field, Operator: "=", Values: []any{true}, Replace: isSet},
+		{Field: field, Operator: "=", Values: []any{false}, Replace: isUnset},
+		{Field: field, Operator: "!=", Values: []any{true}, Replace: isUnset},
+		{Field: field, Operator: "!=", Values: []any{false}, Replace: isSet},
+		{Field: field, Unsupported: fmt.Sprintf("%s became the %s counter; only '=' and '!=' against True or False can be migrated", field, rank)},
+	}
+}
+
+// Migrate applies each step's rules, in order, to every leaf of a domain
+// including leaves of nested 'any' and 'not any' domains. Leaves on a field
+// that has rules but matched none of them are reported as issues and kept
+// unchanged, as are nested leaves whose field only has rules at the top
+// level, since the relation may point back to the same model.
+func Migrate(terms []any, steps ...MigrationStep) ([]any, MigrationReport, error) {
+	var report MigrationReport
+	out := terms
+	for _, step := range steps {
+		migrated, err := migrateStep(out, step, &report)
+		if err != nil {
+			return []any{}, report, err
+		}
+		out = migrated
+	}
+	if len(steps) == 0 {
+		out = cloneDomain(terms)
+	}
+	return out, report, nil
+}
+
+func migrateStep(terms []any, step MigrationStep, report *MigrationReport) ([]any, error) {
+	byField := map[string][]MigrationRule{}
+	prefixRenames := map[string]string{}
+	for _, rule := range step.Rules {
+		if rule.Field == "" {
+			return nil, fmt.Errorf("%w: migration rule without field in step %s", ErrInvalidMapping, step.name())
+		}
+		byField[rule.Field] = append(byField[rule.Field], rule)
+		if rule.Rename != "" && rule.Operator == "" && rule.Values == nil && rule.Replace == nil && rule.Unsupported == "" {
+			_, name := splitLastSegment(rule.Rename)
+			prefixRenames[rule.Field] = name
+		}
+	}
+
+	return Rewrite(terms, func(item WalkItem) ([]any, error) {
+		field, ok := item.Leaf[0].(string)
+		if !ok {
+			return []any{item.Leaf}, nil
+		}
+		keep := []any{item.Leaf}
+		rules := byField[joinScope(item.Scope, field)]
+		if len(rules) == 0 && item.Scope != "" && len(byField[field]) > 0 {
+			// Rules are keyed by path from the domain's model, but the
+			// subdomain may well be on that same model, e.g. child_ids of
+			// res.partner.
+			report.Issues = append(report.Issues, MigrationIssue{
+				Step: step.name(), Path: item.Path, Scope: item.Scope, Leaf: item.Leaf,
+				Reason: fmt.Sprintf("%s has rules on the domain's model but is nested under %s", field, item.Scope),
+			})
+		}
+
+		rule, matched := matchMigrationRule(rules, item.Leaf)
+		switch {
+		case len(rules) > 0 && !matched:
+			report.Issues = append(report.Issues, MigrationIssue{
+				Step: step.name(), Path: item.Path, Scope: item.Scope, Leaf: item.Leaf,
+				Reason: fmt.Sprintf("no rule for operator %v with value %v", item.Leaf[1], item.Leaf[2]),
+			})
+			return keep, nil
+		case matched && rule.Unsupported != "":
+			report.Issues = append(report.Issues, MigrationIssue{
+				Step: step.name(), Path: item.Path, Scope: item.Scope, Leaf: item.Leaf, Reason: rule.Unsupported,
+			})
+			return keep, nil
+		}
+
+		var after []any
+		switch {
+		case matched && rule.Replace != nil:
+			after = cloneDomain(rule.Replace)
+		default:
+			newField := renamePath(item.Scope, field, prefixRenames)
+			op := item.Leaf[1]
+			if matched {
+				// The matched rule's own Rename takes precedence over the
+				// field's plain rename, already applied by renamePath.
+				if rule.Rename != "" {
+					_, name := splitLastSegment(rule.Rename)
+					newField = replaceLastSegment(newField, name)
+				}
+				if rule.NewOperator != "" {
+					op = rule.NewOperator
+				}
+			}
+			leaf := []any{newField, op, item.Leaf[2]}
+			if reflect.DeepEqual(leaf, item.Leaf) {
+				return keep, nil
+			}
+			after = []any{leaf}
+		}
+		report.Changes = append(report.Changes, MigrationChange{
+			Step: step.name(), Path: item.Path, Scope: item.Scope, Before: item.Leaf, After: after,
+		})
+		return after, nil
+	})
+}
+
+func matchMigrationRule(rules []MigrationRule, leaf []any) (MigrationRule, bool) {
+	for _, rule := range rules {
+		if rule.Operator != "" && rule.Operator != leaf[1] {
+			continue
+		}
+		if rule.Values != nil && !containsValue(rule.Values, leaf[2]) {
+			continue
+		}
+		return rule, true
+	}
+	return MigrationRule{}, false
+}
+
+func containsValue(values []any, v any) bool {
+	if list, isList := v.([]any); isList {
+		for _, item := range list {
+			if containsValue(values, item) {
+				return true
+			}
+		}
+		return false
+	}
+	nv, scalar := normalizeScalar(v)
+	for _, candidate := range values {
+		if nc, ok := normalizeScalar(candidate); ok && scalar {
+			if nc == nv {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func replaceLastSegment(path, name string) string {
+	i := strings.LastIndexByte(path, '.')
+	return path[:i+1] + name
+}
+
+// cloneDomain returns a deep copy of a domain's slices.
+func cloneDomain(terms []any) []any {
+	out := make([]any, len(terms))
+	for i, item := range terms {
+		if list, ok := item.([]any); ok {
+			out[i] = cloneDomain(list)
+			continue
+		}
+		out[i] = item
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package odoosearchdomain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigratePartnerRanks(t *testing.T) {
+	terms, _ := ParseDomain("['|',('customer','=',True),('supplier','!=',False),('name','ilike','a'),('child_ids','any',[('customer','=',False)]),('customer','in',[True])]")
+	got, report, err := Migrate(terms, Odoo13PartnerRanks)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []any{
+		"|", []any{"customer_rank", ">", 0}, []any{"supplier_rank", ">", 0},
+		[]any{"name", "ilike", "a"},
+		// rules are keyed by path from the domain's model, so the nested
+		// leaf is kept and reported
+		[]any{"child_ids", "any", []any{[]any{"customer", "=", false}}},
+		[]any{"customer", "in", []any{true}},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected: %v\n     got: %v", expected, got)
+	}
+	if len(report.Changes) != 2 {
+		t.Errorf("expected 2 changes, got %v", report.Changes)
+	}
+	var paths [][]int
+	for _, issue := range report.Issues {
+		paths = append(paths, issue.Path)
+	}
+	if !reflect.DeepEqual(paths, [][]int{{4, 2, 0}, {5}}) {
+		t.Errorf("expected issues for the nested leaf and the 'in' leaf, got %v", report.Issues)
+	}
+	if len(report.Issues) == 2 && report.Issues[0].Scope != "child_ids" {
+		t.Errorf("expected the nested issue to carry its scope, got %q", report.Issues[0].Scope)
+	}
+}
+
+func TestMigrateSteps(t *testing.T) {
+	steps := []MigrationStep{
+		{From: "15.0", To: "16.0", Rules: []MigrationRule{
+			{Field: "x_ref", Rename: "x_reference"},
+			{Field: "state", Operator: "=", Values: []any{"open"}, Replace: []any{[]any{"state", "=", "posted"}}},
+			{Field: "state", Operator: "=", Values: []any{"proforma"}, Unsupported: "pro-forma state was removed"},
+			{Field: "state", Operator: "in"},
+			{Field: "state", Operator: "=", NewOperator: "=", Values: []any{"draft", "posted", "cancel"}},
+		}},
+		{From: "16.0", To: "17.0", Rules: []MigrationRule{
+			{Field: "x_reference", Operator: "ilike", NewOperator: "=ilike"},
+			{Field: "x_reference", Rename: "ref"},
+		}},
+	}
+	terms, _ := ParseDomain("[('x_ref','ilike','A'),('x_ref.name','=',1),('state','=','open'),('state','=','proforma'),('state','=','paid'),('state','in',['draft'])]")
+	got, report, err := Migrate(terms, steps...)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []any{
+		// the operator rule also takes the field's plain rename
+		[]any{"ref", "=ilike", "A"},
+		[]any{"ref.name", "=", 1},
+		[]any{"state", "=", "posted"},
+		[]any{"state", "=", "proforma"},
+		[]any{"state", "=", "paid"},
+		[]any{"state", "in", []any{"draft"}},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected: %v\n     got: %v", expected, got)
+	}
+	var reasons []string
+	for _, issue := range report.Issues {
+		reasons = append(reasons, issue.Step+": "+issue.Reason)
+	}
+	expectedReasons := []string{
+		"15.0 -> 16.0: pro-forma state was removed",
+		"15.0 -> 16.0: no rule for operator = with value paid",
+	}
+	if !reflect.DeepEqual(expectedReasons, reasons) {
+		t.Errorf("expected issues %v, got %v", expectedReasons, reasons)
+	}
+}
+
+func TestMigrateShippedSteps(t *testing.T) {
+	tests := []struct {
+		step     MigrationStep
+		domain   string
+		expected []any
+		issues   int
+	}{
+		{
+			Odoo17SaleOrderLocked,
+			"[('state','=','done'),('state','!=','sale'),('state','=','draft')]",
+			[]any{
+				"&", []any{"state", "=", "sale"}, []any{"locked", "=", true},
+				"|", []any{"state", "!=", "sale"}, []any{"locked", "=", true},
+				[]any{"state", "=", "draft"},
+			},
+			0,
+		},
+		{
+			Odoo17SaleOrderLocked,
+			"[('state','in',['draft','sent']),('state','not in',['done','cancel'])]",
+			[]any{[]any{"state", "in", []any{"draft", "sent"}}, []any{"state", "not in", []any{"done", "cancel"}}},
+			1,
+		},
+		{
+			Odoo18PartnerMobile,
+			"['|',('mobile','ilike','0475'),('mobile','=',False)]",
+			[]any{"|", []any{"phone", "ilike", "0475"}, []any{"mobile", "=", false}},
+			1,
+		},
+	}
+	for _, tt := range tests {
+		terms, _ := ParseDomain(tt.domain)
+		got, report, err := Migrate(terms, tt.step)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.domain, err)
+		}
+		if !reflect.DeepEqual(tt.expected, got) {
+			t.Errorf("%s\nexpected: %v\n     got: %v", tt.domain, tt.expected, got)
+		}
+		if len(report.Issues) != tt.issues {
+			t.Errorf("%s: expected %d issues, got %v", tt.domain, tt.issues, report.Issues)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNormalFormTooLarge is returned when converting a domain to CNF or DNF
+// would produce more clauses than the configured limit.
+var ErrNormalFormTooLarge = errors.New("normal form too large")
+
+// DefaultNormalFormLimit is the clause limit used by ToCNF and ToDNF when
+// the caller passes a limit of zero or less.
+const DefaultNormalFormLimit = 4096
+
+// ToCNF rewrites a domain into conjunctive normal form: an AND of clauses,
+// each clause being an OR of leaves or negated leaves. The result is an
+// equivalent domain in explicit prefix notation.
+//
+// Distributing OR over AND can grow the domain exponentially. If the result
+// would hold more than limit clauses, ErrNormalFormTooLarge is returned.
+func ToCNF(terms []any, limit int) ([]any, error) {
+	return toNormalForm(terms, limit, "&", "|")
+}
+
+// ToDNF rewrites a domain into disjunctive normal form: an OR of
+// conjunctions of leaves or negated leaves. See ToCNF for the limit.
+func ToDNF(terms []any, limit int) ([]any, error) {
+	return toNormalForm(terms, limit, "|", "&")
+}
+
+func toNormalForm(terms []any, limit int, outer, inner string) ([]any, error) {
+	tree, err := buildTree(terms)
+	if err != nil {
+		return []any{}, err
+	}
+	if tree == nil {
+		return []any{}, nil
+	}
+	clauses, err := distribute(tree.nnf(), outer, normalFormLimit(limit))
+	if err != nil {
+		return []any{}, err
+	}
+	return clausesToTree(clauses, outer, inner).toDomain(), nil
+}
+
+func normalFormLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultNormalFormLimit
+	}
+	return limit
+}
+
+// distribute flattens an NNF tree into clauses of literals. Children of an
+// outer node are concatenated; children of an inner node are combined by
+// cross product, which is where the blow-up limit applies.
+func distribute(n *node, outer string, limit int) ([][]*node, error) {
+	switch n.op {
+	case "", "!":
+		return [][]*node{{n}}, nil
+	case outer:
+		var clauses [][]*node
+		for _, child := range n.children {
+			sub, err := distribute(child, outer, limit)
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, sub...)
+			if len(clauses) > limit {
+				return nil, fmt.Errorf("%w: more than %d clauses", ErrNormalFormTooLarge, limit)
+			}
+		}
+		return dedupeClauses(clauses), nil
+	default:
+		clauses := [][]*node{{}}
+		for _, child := range n.children {
+			sub, err := distribute(child, outer, limit)
+			if err != nil {
+				return nil, err
+			}
+			if len(clauses)*len(sub) > limit {
+				return nil, fmt.Errorf("%w: more than %d clauses", ErrNormalFormTooLarge, limit)
+			}
+			product := make([][]*node, 0, len(clauses)*len(sub))
+			for _, left := range clauses {
+				for _, right := range sub {
+					clause := make([]*node, 0, len(left)+len(right))
+					clause = append(clause, left...)
+					clause = append(clause, right...)
+					product = append(product, dedupeLiterals(clause))
+				}
+			}
+			clauses = dedupeClauses(product)
+		}
+		return clauses, nil
+	}
+}
+
+func literalKey(n *node) string {
+	return fmt.Sprintf("%#v", n.toDomain())
+}
+
+func clauseKey(clause []*node) string {
+	key := ""
+	for _, lit := range clause {
+		key += literalKey(lit) + ";"
+	}
+	return key
+}
+
+func dedupeLiterals(clause []*node) []*node {
+	seen := make(map[string]bool, len(clause))
+	out := clause[:0]
+	for _, lit := range clause {
+		key := literalKey(lit)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, lit)
+	}
+	return out
+}
+
+func dedupeClauses(clauses [][]*node) [][]*node {
+	seen := make(map[string]bool, len(clauses))
+	out := clauses[:0]
+	for _, clause := range clauses {
+		key := clauseKey(clause)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, clause)
+	}
+	return out
+}
+
+func clausesToTree(clauses [][]*node, outer, inner string) *node {
+	groups := make([]*node, len(clauses))
+	for i, clause := range clauses {
+		groups[i] = newNary(inner, clause...)
+	}
+	return newNary(outer, groups...)
+}
@@ -0,0 +1,94 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var (
+	leafA = []any{"a", "=", 1}
+	leafB = []any{"b", "=", 2}
+	leafC = []any{"c", "=", 3}
+	leafD = []any{"d", "=", 4}
+)
+
+var normalFormPatterns = []struct {
+	domain []any
+	cnf    []any
+	dnf    []any
+}{
+	{[]any{}, []any{}, []any{}},
+	{[]any{leafA}, []any{leafA}, []any{leafA}},
+	// implicit AND
+	{[]any{leafA, leafB}, []any{"&", leafA, leafB}, []any{"&", leafA, leafB}},
+	// a | (b & c)
+	{
+		[]any{"|", leafA, "&", leafB, leafC},
+		[]any{"&", "|", leafA, leafB, "|", leafA, leafC},
+		[]any{"|", leafA, "&", leafB, leafC},
+	},
+	// (a | b) & (c | d)
+	{
+		[]any{"|", leafA, leafB, "|", leafC, leafD},
+		[]any{"&", "|", leafA, leafB, "|", leafC, leafD},
+		[]any{"|", "|", "|", "&", leafA, leafC, "&", leafA, leafD, "&", leafB, leafC, "&", leafB, leafD},
+	},
+	// !(a & b) pushes NOT through with exact operator negation
+	{
+		[]any{"!", "&", leafA, leafB},
+		[]any{"|", []any{"a", "!=", 1}, []any{"b", "!=", 2}},
+		[]any{"|", []any{"a", "!=", 1}, []any{"b", "!=", 2}},
+	},
+	// range operators have no exact complement and keep their '!'
+	{
+		[]any{"!", []any{"qty", "<", 5}},
+		[]any{"!", []any{"qty", "<", 5}},
+		[]any{"!", []any{"qty", "<", 5}},
+	},
+	// double negation disappears, duplicate literals collapse
+	{
+		[]any{"&", "!", "!", leafA, leafA},
+		[]any{leafA},
+		[]any{leafA},
+	},
+}
+
+func TestNormalForms(t *testing.T) {
+	for i, pattern := range normalFormPatterns {
+		cnf, err := ToCNF(pattern.domain, 0)
+		if err != nil {
+			t.Errorf("[%d] ToCNF: unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(pattern.cnf, cnf) {
+			t.Errorf("[%d] ToCNF\nexpected: %v\n     got: %v", i, pattern.cnf, cnf)
+		}
+		dnf, err := ToDNF(pattern.domain, 0)
+		if err != nil {
+			t.Errorf("[%d] ToDNF: unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(pattern.dnf, dnf) {
+			t.Errorf("[%d] ToDNF\nexpected: %v\n     got: %v", i, pattern.dnf, dnf)
+		}
+	}
+}
+
+func TestNormalFormLimit(t *testing.T) {
+	// (a1|b1) & (a2|b2) & ... has 2^n DNF clauses.
+	var domain []any
+	for i := range 16 {
+		domain = append(domain, "|", []any{"a", "=", i}, []any{"b", "=", i})
+	}
+	if _, err := ToDNF(domain, 1000); !errors.Is(err, ErrNormalFormTooLarge) {
+		t.Errorf("expected ErrNormalFormTooLarge, got %v", err)
+	}
+	if _, err := ToCNF(domain, 1000); err != nil {
+		t.Errorf("CNF of a conjunction should stay small, got %v", err)
+	}
+}
+
+func TestNormalFormInvalidDomain(t *testing.T) {
+	if _, err := ToCNF([]any{"|", leafA}, 0); err != ErrNotEnoughAndOrTerms {
+		t.Errorf("expected ErrNotEnoughAndOrTerms, got %v", err)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
+	"unicode/utf8"
 )
 
 // Sentinel errors for domain parsing and validation.
@@ -13,8 +15,14 @@ var (
 	ErrSyntax              = errors.New("invalid syntax")
 	ErrNotEnoughAndOrTerms = errors.New("not enough AND/OR terms")
 	ErrNotEnoughNotTerms   = errors.New("not enough NOT terms")
+	ErrLimitExceeded       = errors.New("parse limit exceeded")
 )
 
+// limitError reports which ParseOptions limit was hit and where.
+func limitError(name string, limit, pos int) error {
+	return fmt.Errorf("%w: %s of %d exceeded at position %d", ErrLimitExceeded, name, limit, pos)
+}
+
 // ============================================================
 // Phase 1: Lexer — single-pass tokenizer, O(n)
 // ============================================================
@@ -42,36 +50,59 @@ type token struct {
 	pos int    // byte position in input (for error messages)
 }
 
+// lexer scans the input as UTF-8 bytes. Tokens slice their text from the
+// input; only strings containing escapes are copied.
 type lexer struct {
-	input []rune
-	pos   int
+	input    string
+	pos      int // byte offset
+	depth    int // open brackets and parentheses
+	maxDepth int // 0 means unlimited
 }
 
 func newLexer(input string) *lexer {
-	return &lexer{input: []rune(input)}
+	return &lexer{input: input}
 }
 
-func (l *lexer) peek() rune {
-	if l.pos >= len(l.input) {
-		return 0
-	}
-	return l.input[l.pos]
+// maxPooledTokens keeps buffers grown by unusually large inputs out of
+// tokenPool.
+const maxPooledTokens = 4096
+
+// tokenPool recycles token buffers between parses.
+var tokenPool = sync.Pool{
+	New: func() any {
+		tokens := make([]token, 0, 64)
+		return &tokens
+	},
 }
 
-func (l *lexer) advance() rune {
-	r := l.input[l.pos]
-	l.pos++
-	return r
+// peekRune decodes the rune at the current position.
+func (l *lexer) peekRune() (rune, int) {
+	if c := l.input[l.pos]; c < utf8.RuneSelf {
+		return rune(c), 1
+	}
+	return utf8.DecodeRuneInString(l.input[l.pos:])
 }
 
 func (l *lexer) skipWhitespace() {
-	for l.pos < len(l.input) && unicode.IsSpace(l.input[l.pos]) {
-		l.pos++
+	for l.pos < len(l.input) {
+		switch c := l.input[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f':
+			l.pos++
+		case c < utf8.RuneSelf:
+			return
+		default:
+			r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+			if !unicode.IsSpace(r) {
+				return
+			}
+			l.pos += size
+		}
 	}
 }
 
-func (l *lexer) tokenize() ([]token, error) {
-	var tokens []token
+// tokenize appends the tokens of the input to tokens, which may be a
+// recycled buffer.
+func (l *lexer) tokenize(tokens []token) ([]token, error) {
 	for {
 		l.skipWhitespace()
 		if l.pos >= len(l.input) {
@@ -79,71 +110,111 @@ func (l *lexer) tokenize() ([]token, error) {
 			return tokens, nil
 		}
 
-		r := l.peek()
 		startPos := l.pos
-
-		switch {
-		case r == '[':
-			l.advance()
+		switch c := l.input[l.pos]; c {
+		case '[':
+			if err := l.open(); err != nil {
+				return tokens, err
+			}
 			tokens = append(tokens, token{typ: tokLBracket, pos: startPos})
-		case r == ']':
-			l.advance()
+		case ']':
+			l.close()
 			tokens = append(tokens, token{typ: tokRBracket, pos: startPos})
-		case r == '(':
-			l.advance()
+		case '(':
+			if err := l.open(); err != nil {
+				return tokens, err
+			}
 			tokens = append(tokens, token{typ: tokLParen, pos: startPos})
-		case r == ')':
-			l.advance()
+		case ')':
+			l.close()
 			tokens = append(tokens, token{typ: tokRParen, pos: startPos})
-		case r == ',':
-			l.advance()
+		case ',':
+			l.pos++
 			tokens = append(tokens, token{typ: tokComma, pos: startPos})
-		case r == '\'' || r == '"':
-			tok, err := l.lexString(r)
+		case '\'', '"':
+			tok, err := l.lexString(c)
 			if err != nil {
-				return nil, err
+				return tokens, err
 			}
 			tokens = append(tokens, tok)
-		case r == '-' || unicode.IsDigit(r):
-			tok, err := l.lexNumber()
-			if err != nil {
-				return nil, err
+		default:
+			r, _ := l.peekRune()
+			var tok token
+			var err error
+			switch {
+			case r == '-' || unicode.IsDigit(r):
+				tok, err = l.lexNumber()
+			case unicode.IsLetter(r):
+				tok, err = l.lexKeyword()
+			default:
+				err = fmt.Errorf("%w: unexpected character %q at position %d", ErrSyntax, string(r), l.pos)
 			}
-			tokens = append(tokens, tok)
-		case unicode.IsLetter(r):
-			tok, err := l.lexKeyword()
 			if err != nil {
-				return nil, err
+				return tokens, err
 			}
 			tokens = append(tokens, tok)
-		default:
-			return nil, fmt.Errorf("%w: unexpected character %q at position %d", ErrSyntax, string(r), l.pos)
 		}
 	}
 }
 
+// open consumes an opening bracket or parenthesis, enforcing maxDepth.
+func (l *lexer) open() error {
+	l.depth++
+	if l.maxDepth > 0 && l.depth > l.maxDepth {
+		return limitError("MaxDepth", l.maxDepth, l.pos)
+	}
+	l.pos++
+	return nil
+}
+
+// close consumes a closing bracket or parenthesis.
+func (l *lexer) close() {
+	if l.depth > 0 {
+		l.depth--
+	}
+	l.pos++
+}
+
 // lexString reads a single-quoted or double-quoted string with escape support.
-func (l *lexer) lexString(quote rune) (token, error) {
+// Strings without a backslash are sliced from the input without copying.
+func (l *lexer) lexString(quote byte) (token, error) {
 	startPos := l.pos
-	l.advance() // skip opening quote
+	l.pos++ // skip opening quote
+	for i := l.pos; i < len(l.input); i++ {
+		switch l.input[i] {
+		case quote:
+			str := l.input[l.pos:i]
+			l.pos = i + 1
+			return token{typ: tokString, str: str, pos: startPos}, nil
+		case '\\':
+			return l.lexEscapedString(quote, startPos)
+		}
+	}
+	return token{}, fmt.Errorf("%w: unterminated string starting at position %d", ErrSyntax, startPos)
+}
+
+// lexEscapedString reads the rest of a string containing escapes. \', \"
+// and \\ stand for the escaped character; other backslashes are kept.
+func (l *lexer) lexEscapedString(quote byte, startPos int) (token, error) {
 	var sb strings.Builder
+	sb.Grow(len(l.input) - l.pos)
 	for l.pos < len(l.input) {
-		r := l.advance()
-		if r == '\\' && l.pos < len(l.input) {
-			next := l.advance()
-			switch next {
+		c := l.input[l.pos]
+		l.pos++
+		if c == '\\' && l.pos < len(l.input) {
+			switch next := l.input[l.pos]; next {
 			case '\'', '"', '\\':
-				sb.WriteRune(next)
+				sb.WriteByte(next)
+				l.pos++
 			default:
-				sb.WriteRune('\\')
-				sb.WriteRune(next)
+				sb.WriteByte('\\')
 			}
 			continue
 		}
-		if r == quote {
+		if c == quote {
 			return token{typ: tokString, str: sb.String(), pos: startPos}, nil
 		}
-		sb.WriteRune(r)
+		sb.WriteByte(c)
 	}
 	return token{}, fmt.Errorf("%w: unterminated string starting at position %d", ErrSyntax, startPos)
 }
@@ -151,43 +222,52 @@ func (l *lexer) lexString(quote rune) (token, error) {
 // lexNumber reads an integer or float, optionally preceded by a minus sign.
 func (l *lexer) lexNumber() (token, error) {
 	startPos := l.pos
-	var sb strings.Builder
-
-	if l.peek() == '-' {
-		sb.WriteRune(l.advance())
+	if l.input[l.pos] == '-' {
+		l.pos++
 	}
 
-	if l.pos >= len(l.input) || !unicode.IsDigit(l.peek()) {
+	if !l.skipDigits() {
 		return token{}, fmt.Errorf("%w: expected digit after '-' at position %d", ErrSyntax, startPos)
 	}
 
-	for l.pos < len(l.input) && unicode.IsDigit(l.peek()) {
-		sb.WriteRune(l.advance())
-	}
-
 	// Check for decimal point → float
-	if l.pos < len(l.input) && l.peek() == '.' {
-		sb.WriteRune(l.advance())
-		if l.pos >= len(l.input) || !unicode.IsDigit(l.peek()) {
+	if l.pos < len(l.input) && l.input[l.pos] == '.' {
+		l.pos++
+		if !l.skipDigits() {
 			return token{}, fmt.Errorf("%w: expected digit after '.' at position %d", ErrSyntax, startPos)
 		}
-		for l.pos < len(l.input) && unicode.IsDigit(l.peek()) {
-			sb.WriteRune(l.advance())
-		}
-		return token{typ: tokFloat, str: sb.String(), pos: startPos}, nil
+		return token{typ: tokFloat, str: l.input[startPos:l.pos], pos: startPos}, nil
 	}
 
-	return token{typ: tokInt, str: sb.String(), pos: startPos}, nil
+	return token{typ: tokInt, str: l.input[startPos:l.pos], pos: startPos}, nil
+}
+
+// skipDigits consumes a run of digits and reports whether it was non-empty.
+// Non-ASCII digits are consumed too, and rejected when the number is
+// converted.
+func (l *lexer) skipDigits() bool {
+	start := l.pos
+	for l.pos < len(l.input) {
+		r, size := l.peekRune()
+		if !unicode.IsDigit(r) {
+			break
+		}
+		l.pos += size
+	}
+	return l.pos > start
 }
 
 // lexKeyword reads an alphabetic identifier and maps it to True/False/None.
 func (l *lexer) lexKeyword() (token, error) {
 	startPos := l.pos
-	var sb strings.Builder
-	for l.pos < len(l.input) && (unicode.IsLetter(l.peek()) || l.peek() == '_') {
-		sb.WriteRune(l.advance())
+	for l.pos < len(l.input) {
+		r, size := l.peekRune()
+		if !unicode.IsLetter(r) && r != '_' {
+			break
+		}
+		l.pos += size
 	}
-	word := sb.String()
+	word := l.input[startPos:l.pos]
 	switch word {
 	case "True", "true":
 		return token{typ: tokTrue, str: word, pos: startPos}, nil
@@ -215,10 +295,13 @@ var validComparators = map[string]bool{
 type parser struct {
 	tokens []token
 	pos    int
+	opts   ParseOptions
+	leaves int // terms parsed so far, including nested domains
+	nested int // nested domains parsed so far
 }
 
-func newParser(tokens []token) *parser {
-	return &parser{tokens: tokens}
+func newParser(tokens []token, opts ParseOptions) *parser {
+	return &parser{tokens: tokens, opts: opts}
 }
 
 func (p *parser) peek() token {
@@ -228,152 +311,37 @@ func (p *parser) peek() token {
 	return p.tokens[p.pos]
 }
 
-func (p *parser) peekAt(offset int) token {
-	idx := p.pos + offset
-	if idx >= len(p.tokens) {
-		return token{typ: tokEOF}
-	}
-	return p.tokens[idx]
-}
-
 func (p *parser) advance() token {
 	t := p.tokens[p.pos]
 	p.pos++
 	return t
 }
 
-func (p *parser) expect(typ tokenType) (token, error) {
-	t := p.peek()
-	if t.typ != typ {
-		return t, fmt.Errorf("%w: unexpected token at position %d", ErrSyntax, t.pos)
-	}
-	return p.advance(), nil
+// tuple is a parenthesized literal. Tuples are only valid as domain terms;
+// the enclosing list turns them into []any when it is classified.
+type tuple struct {
+	items []any
+	pos   int
 }
 
-// parseDomain parses: '[' items ']' | '[' ']' | '[' '(' ')' ']'
+// parseDomain parses the top-level domain: '[' items ']' | '[' ']' | '[' '(' ')' ']'
 func (p *parser) parseDomain() ([]any, error) {
-	if _, err := p.expect(tokLBracket); err != nil {
-		return nil, err
-	}
-
-	// Empty: []
-	if p.peek().typ == tokRBracket {
-		p.advance()
-		return []any{}, nil
-	}
-
-	// Empty with parens: [()]
-	if p.peek().typ == tokLParen && p.peekAt(1).typ == tokRParen && p.peekAt(2).typ == tokRBracket {
-		p.advance() // (
-		p.advance() // )
-		p.advance() // ]
-		return []any{}, nil
-	}
-
-	items, err := p.parseItems()
-	if err != nil {
-		return nil, err
-	}
-
-	if _, err := p.expect(tokRBracket); err != nil {
-		return nil, err
-	}
-
-	return items, nil
-}
-
-// parseItems parses: item (',' item)*
-func (p *parser) parseItems() ([]any, error) {
-	var items []any
-
-	item, err := p.parseItem()
-	if err != nil {
-		return nil, err
-	}
-	items = append(items, item...)
-
-	for p.peek().typ == tokComma {
-		p.advance() // consume comma
-		// Allow trailing comma before ]
-		if p.peek().typ == tokRBracket {
-			break
-		}
-		item, err := p.parseItem()
-		if err != nil {
-			return nil, err
-		}
-		items = append(items, item...)
-	}
-
-	return items, nil
-}
-
-// parseItem parses a single domain element: either a connector ('&','|','!') or a term tuple.
-// Returns a slice so connectors (1 element) and terms (1 element) can be appended uniformly.
-func (p *parser) parseItem() ([]any, error) {
-	t := p.peek()
-
-	// Connector: a quoted string whose content is &, |, or !
-	if t.typ == tokString && (t.str == "&" || t.str == "|" || t.str == "!") {
-		p.advance()
-		return []any{t.str}, nil
-	}
-
-	// Term tuple: ('field', 'operator', value)
-	if t.typ == tokLParen {
-		term, err := p.parseTerm()
-		if err != nil {
-			return nil, err
-		}
-		return []any{term}, nil
-	}
-
-	return nil, fmt.Errorf("%w: expected '(' or connector at position %d", ErrSyntax, t.pos)
-}
-
-// parseTerm parses: '(' STRING ',' STRING ',' value ')'
-func (p *parser) parseTerm() ([]any, error) {
-	if _, err := p.expect(tokLParen); err != nil {
-		return nil, err
-	}
-
-	// Field name
-	fieldTok, err := p.expect(tokString)
-	if err != nil {
-		return nil, fmt.Errorf("%w: expected field name string", ErrSyntax)
-	}
-
-	if _, err := p.expect(tokComma); err != nil {
-		return nil, err
+	open := p.peek()
+	if open.typ != tokLBracket {
+		return nil, fmt.Errorf("%w: unexpected token at position %d", ErrSyntax, open.pos)
 	}
-
-	// Operator
-	opTok, err := p.expect(tokString)
-	if err != nil {
-		return nil, fmt.Errorf("%w: expected operator string", ErrSyntax)
-	}
-	if !validComparators[opTok.str] {
-		return nil, fmt.Errorf("%w: unknown operator %q", ErrSyntax, opTok.str)
-	}
-
-	if _, err := p.expect(tokComma); err != nil {
-		return nil, err
-	}
-
-	// Value
-	value, err := p.parseValue()
+	items, trailing, err := p.parseElements(tokRBracket)
 	if err != nil {
 		return nil, err
 	}
-
-	if _, err := p.expect(tokRParen); err != nil {
-		return nil, err
-	}
-
-	return []any{fieldTok.str, opTok.str, value}, nil
+	return p.domainItems(items, trailing, open.pos)
 }
 
-// parseValue parses: STRING | INT | FLOAT | TRUE | FALSE | NONE | list-or-domain
+// parseValue parses: STRING | INT | FLOAT | TRUE | FALSE | NONE | list | tuple
+//
+// Values are parsed as a generic literal tree in a single pass. Whether a
+// bracketed value is a plain list or a nested domain is decided when its
+// closing bracket is reached, so no input is ever scanned twice.
 func (p *parser) parseValue() (any, error) {
 	t := p.peek()
 
@@ -405,67 +373,156 @@ func (p *parser) parseValue() (any, error) {
 		p.advance()
 		return nil, nil
 	case tokLBracket:
-		return p.parseListOrDomain()
+		return p.parseList()
+	case tokLParen:
+		return p.parseTuple()
 	default:
 		return nil, fmt.Errorf("%w: unexpected token in value position at %d", ErrSyntax, t.pos)
 	}
 }
 
-// parseListOrDomain disambiguates between a nested domain [(...), ...] and a plain list [1,2,3].
-// If the first element after [ is '(' or a connector string, it tries domain parsing first.
-func (p *parser) parseListOrDomain() (any, error) {
-	next := p.peekAt(1) // token after '['
+// parseElements parses: open (value (',' value)* ','?)? close
+// and reports whether the last element was followed by a comma.
+func (p *parser) parseElements(close tokenType) (items []any, trailing bool, err error) {
+	p.advance() // opening bracket or parenthesis
+	items = []any{}
+	values := 0
+	for p.peek().typ != close {
+		if p.peek().typ != tokLParen {
+			if p.opts.MaxListLength > 0 && values == p.opts.MaxListLength {
+				return nil, false, limitError("MaxListLength", p.opts.MaxListLength, p.peek().pos)
+			}
+			values++
+		}
+		val, err := p.parseValue()
+		if err != nil {
+			return nil, false, err
+		}
+		items = append(items, val)
 
-	// If first element looks like a domain (tuple start or connector), try domain parse.
-	if next.typ == tokLParen || (next.typ == tokString && (next.str == "&" || next.str == "|" || next.str == "!")) {
-		savePos := p.pos
-		domain, err := p.parseDomain()
-		if err == nil {
-			return domain, nil
+		trailing = false
+		switch p.peek().typ {
+		case tokComma:
+			p.advance()
+			trailing = true
+		case close:
+		default:
+			return nil, false, fmt.Errorf("%w: unexpected token at position %d", ErrSyntax, p.peek().pos)
 		}
-		p.pos = savePos // backtrack on failure
 	}
-
-	// Otherwise parse as a plain value list.
-	return p.parseList()
+	p.advance() // closing bracket or parenthesis
+	return items, trailing, nil
 }
 
-// parseList parses: '[' value (',' value)* ']' | '[' ']'
-func (p *parser) parseList() ([]any, error) {
-	if _, err := p.expect(tokLBracket); err != nil {
-		return nil, err
+// parseTuple parses: '(' (value (',' value)*)? ')'
+func (p *parser) parseTuple() (tuple, error) {
+	pos := p.peek().pos
+	items, trailing, err := p.parseElements(tokRParen)
+	if err != nil {
+		return tuple{}, err
 	}
-
-	// Empty list
-	if p.peek().typ == tokRBracket {
-		p.advance()
-		return []any{}, nil
+	if trailing {
+		return tuple{}, fmt.Errorf("%w: unexpected ',' before ')' at position %d", ErrSyntax, pos)
+	}
+	if len(items) == 3 {
+		// Every valid 3-tuple is a term; count it as soon as it is read.
+		p.leaves++
+		if p.opts.MaxLeaves > 0 && p.leaves > p.opts.MaxLeaves {
+			return tuple{}, limitError("MaxLeaves", p.opts.MaxLeaves, pos)
+		}
 	}
+	return tuple{items: items, pos: pos}, nil
+}
 
-	var items []any
-	val, err := p.parseValue()
+// parseList parses a bracketed value: a nested domain when it contains
+// tuples, otherwise a plain list.
+func (p *parser) parseList() ([]any, error) {
+	pos := p.peek().pos
+	items, trailing, err := p.parseElements(tokRBracket)
 	if err != nil {
 		return nil, err
 	}
-	items = append(items, val)
-
-	for p.peek().typ == tokComma {
-		p.advance() // consume comma
-		if p.peek().typ == tokRBracket {
-			break // trailing comma
+	for _, item := range items {
+		if _, isTuple := item.(tuple); isTuple {
+			p.nested++
+			if p.opts.MaxNestedDomains > 0 && p.nested > p.opts.MaxNestedDomains {
+				return nil, limitError("MaxNestedDomains", p.opts.MaxNestedDomains, pos)
+			}
+			return p.domainItems(items, trailing, pos)
 		}
-		val, err := p.parseValue()
-		if err != nil {
-			return nil, err
+	}
+	return items, nil
+}
+
+// domainItems classifies the elements of a bracketed list as domain items:
+// connectors and terms. A single empty tuple, [()], is the empty domain.
+func (p *parser) domainItems(items []any, trailing bool, pos int) ([]any, error) {
+	if len(items) == 1 && !trailing {
+		if t, ok := items[0].(tuple); ok && len(t.items) == 0 {
+			return []any{}, nil
 		}
-		items = append(items, val)
 	}
 
-	if _, err := p.expect(tokRBracket); err != nil {
-		return nil, err
+	out := make([]any, len(items))
+	var pending []int // operands still expected by enclosing connectors
+	for i, item := range items {
+		switch v := item.(type) {
+		case string:
+			switch v {
+			case "&", "|":
+				pending = append(pending, 2)
+			case "!":
+				pending = append(pending, 1)
+			default:
+				return nil, fmt.Errorf("%w: expected '(' or connector in domain at position %d", ErrSyntax, pos)
+			}
+			// MaxDepth also bounds prefix nesting, which validateAt walks
+			// recursively.
+			if p.opts.MaxDepth > 0 && len(pending) > p.opts.MaxDepth {
+				return nil, limitError("MaxDepth", p.opts.MaxDepth, pos)
+			}
+			out[i] = v
+		case tuple:
+			term, err := termFromTuple(v)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = term
+			// A complete operand: close the connectors it completes.
+			for len(pending) > 0 {
+				pending[len(pending)-1]--
+				if pending[len(pending)-1] > 0 {
+					break
+				}
+				pending = pending[:len(pending)-1]
+			}
+		default:
+			return nil, fmt.Errorf("%w: expected '(' or connector in domain at position %d", ErrSyntax, pos)
+		}
 	}
+	return out, nil
+}
 
-	return items, nil
+// termFromTuple checks a term: (STRING, STRING, value)
+func termFromTuple(t tuple) ([]any, error) {
+	if len(t.items) != 3 {
+		return nil, fmt.Errorf("%w: expected a (field, operator, value) term at position %d", ErrSyntax, t.pos)
+	}
+	field, ok := t.items[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected field name string", ErrSyntax)
+	}
+	op, ok := t.items[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected operator string", ErrSyntax)
+	}
+	if !validComparators[op] {
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrSyntax, op)
+	}
+	if _, isTuple := t.items[2].(tuple); isTuple {
+		return nil, fmt.Errorf("%w: unexpected tuple in value position at %d", ErrSyntax, t.items[2].(tuple).pos)
+	}
+	return []any{field, op, t.items[2]}, nil
 }
 
 // ============================================================
@@ -533,6 +590,26 @@ func validateAt(terms []any, pos int) (int, error) {
 // Public API
 // ============================================================
 
+// ParseOptions bounds the resources spent parsing untrusted input. Each
+// limit is enforced while lexing or parsing, as soon as it is crossed; zero
+// means unlimited.
+type ParseOptions struct {
+	// MaxInputBytes limits the length of the input string.
+	MaxInputBytes int
+	// MaxDepth limits both the nesting of brackets and parentheses and the
+	// nesting of '&', '|' and '!' connectors within a domain.
+	MaxDepth int
+	// MaxLeaves limits the number of terms, counting those of nested
+	// domains.
+	MaxLeaves int
+	// MaxListLength limits the number of elements of each value list, and
+	// the number of connectors of each domain.
+	MaxListLength int
+	// MaxNestedDomains limits the number of domains in value position, as
+	// used by 'any' and 'not any'.
+	MaxNestedDomains int
+}
+
 // ParseDomain parses an Odoo search domain string into a slice of terms and connectors.
 //
 // The domain string should be formatted according to the Odoo search domain syntax:
@@ -543,14 +620,41 @@ func validateAt(terms []any, pos int) (int, error) {
 // Returns ErrSyntax for structurally invalid domains that begin with '['.
 // Returns ErrNotEnoughAndOrTerms / ErrNotEnoughNotTerms for prefix-notation arity violations.
 func ParseDomain(domain string) (filter []any, err error) {
+	return ParseDomainWithOptions(domain, ParseOptions{})
+}
+
+// ParseDomainWithOptions is ParseDomain with resource limits. When a limit is
+// hit it returns an error wrapping ErrLimitExceeded that names the limit.
+// Input without outer brackets still yields an empty slice and no error,
+// except when it is longer than MaxInputBytes.
+func ParseDomainWithOptions(domain string, opts ParseOptions) (filter []any, err error) {
+	if opts.MaxInputBytes > 0 && len(domain) > opts.MaxInputBytes {
+		return []any{}, limitError("MaxInputBytes", opts.MaxInputBytes, opts.MaxInputBytes)
+	}
 	domain = strings.TrimSpace(domain)
 	if domain == "" {
 		return []any{}, nil
 	}
 
 	// Tokenize
-	tokens, lexErr := newLexer(domain).tokenize()
+	lex := newLexer(domain)
+	lex.maxDepth = opts.MaxDepth
+	buf := tokenPool.Get().(*[]token)
+	tokens, lexErr := lex.tokenize((*buf)[:0])
+	defer func() {
+		if cap(tokens) > maxPooledTokens {
+			return
+		}
+		clear(tokens) // drop references to the input
+		*buf = tokens[:0]
+		tokenPool.Put(buf)
+	}()
 	if lexErr != nil {
+		// Input without an outer '[' is not a domain, even when lexing it
+		// crossed a limit.
+		if errors.Is(lexErr, ErrLimitExceeded) && domain[0] == '[' {
+			return []any{}, lexErr
+		}
 		// Lex failure on input without brackets is not an error — just not a domain.
 		return []any{}, nil
 	}
@@ -561,9 +665,12 @@ func ParseDomain(domain string) (filter []any, err error) {
 	}
 
 	// Parse
-	p := newParser(tokens)
+	p := newParser(tokens, opts)
 	result, parseErr := p.parseDomain()
 	if parseErr != nil {
+		if errors.Is(parseErr, ErrLimitExceeded) {
+			return []any{}, parseErr
+		}
 		if errors.Is(parseErr, ErrSyntax) {
 			return []any{}, ErrSyntax
 		}
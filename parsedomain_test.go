@@ -1,7 +1,10 @@
 package odoosearchdomain
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -83,6 +86,21 @@ var searchDomainPatterns = []struct {
 
 	// Value of 0
 	{"[('qty','=',0)]", []any{[]any{"qty", "=", 0}}, nil, []any{}},
+
+	// Lists versus nested domains
+	{"[('tag','in',['&','|'])]", []any{[]any{"tag", "in", []any{"&", "|"}}}, nil, []any{}},
+	{"[('tag','in',['!',1])]", []any{[]any{"tag", "in", []any{"!", 1}}}, nil, []any{}},
+	{"[('line_ids','any',[()])]", []any{[]any{"line_ids", "any", []any{}}}, nil, []any{}},
+	{"[('line_ids','any',['!',('a','=',[1,2]),])]", []any{[]any{"line_ids", "any", []any{"!", []any{"a", "=", []any{1, 2}}}}}, nil, []any{}},
+	{"[('line_ids','any',[('a','=',1),5])]", []any{}, ErrSyntax, []any{}},
+	{"[('line_ids','any',[5,('a','=',1)])]", []any{}, ErrSyntax, []any{}},
+	{"[('line_ids','any',[(),('a','=',1)])]", []any{}, ErrSyntax, []any{}},
+	{"[('line_ids','any',[(),])]", []any{}, ErrSyntax, []any{}},
+	{"[('a','=',(1,2))]", []any{}, ErrSyntax, []any{}},
+	{"[('a','=',1,)]", []any{}, ErrSyntax, []any{}},
+	{"[('a','=',[1,,2])]", []any{}, ErrSyntax, []any{}},
+	{"[1,2]", []any{}, ErrSyntax, []any{}},
+	{"[(),]", []any{}, ErrSyntax, []any{}},
 }
 
 // TestSearchDomain tests the ParseDomain function with various search domain patterns.
@@ -98,6 +116,122 @@ func TestSearchDomain(t *testing.T) {
 	}
 }
 
+func TestParseDomainWithOptions(t *testing.T) {
+	deepNot := "[" + strings.Repeat("'!',", 50) + "('a','=',1)]"
+	deepAny := "[('a','=',1)]"
+	for range 5 {
+		deepAny = "[('child_ids','any'," + deepAny + ")]"
+	}
+	tests := []struct {
+		name   string
+		domain string
+		opts   ParseOptions
+		limit  string // name of the limit expected in the error, "" for success
+	}{
+		{"unlimited", deepNot, ParseOptions{}, ""},
+		{"input bytes", "[('a','=',1)]", ParseOptions{MaxInputBytes: 10}, "MaxInputBytes"},
+		{"input bytes at limit", "[('a','=',1)]", ParseOptions{MaxInputBytes: 13}, ""},
+		{"bracket depth", deepAny, ParseOptions{MaxDepth: 8}, "MaxDepth"},
+		{"prefix depth", deepNot, ParseOptions{MaxDepth: 20}, "MaxDepth"},
+		{"right-nested OR chain", "['|',('a','=',1),'|',('b','=',2),'|',('c','=',3),('d','=',4)]", ParseOptions{MaxDepth: 2}, "MaxDepth"},
+		{"balanced tree within depth", "['|','&',('a','=',1),('b','=',2),'&',('c','=',3),('d','=',4)]", ParseOptions{MaxDepth: 2}, ""},
+		{"leaves", "[('a','=',1),('b','=',2),('c','=',3)]", ParseOptions{MaxLeaves: 2}, "MaxLeaves"},
+		{"leaves count nested domains", "[('a','any',[('b','=',1),('c','=',2)])]", ParseOptions{MaxLeaves: 2}, "MaxLeaves"},
+		{"list length", "[('id','in',[1,2,3,4])]", ParseOptions{MaxListLength: 3}, "MaxListLength"},
+		{"list length at limit", "[('id','in',[1,2,3])]", ParseOptions{MaxListLength: 3}, ""},
+		{"nested domains", deepAny, ParseOptions{MaxNestedDomains: 4}, "MaxNestedDomains"},
+		{"nested domains at limit", deepAny, ParseOptions{MaxNestedDomains: 5}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDomainWithOptions(tt.domain, tt.opts)
+			if tt.limit == "" {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if len(got) == 0 {
+					t.Errorf("expected a parsed domain")
+				}
+				return
+			}
+			if !errors.Is(err, ErrLimitExceeded) || !strings.Contains(err.Error(), tt.limit) {
+				t.Errorf("expected ErrLimitExceeded for %s, got %v", tt.limit, err)
+			}
+		})
+	}
+
+	// Input that is not a domain stays an empty result under any limit.
+	for _, domain := range []string{"((((((", "(('a','=',1))", "x[[[[[["} {
+		got, err := ParseDomainWithOptions(domain, ParseOptions{MaxDepth: 2})
+		if err != nil || len(got) != 0 {
+			t.Errorf("%q: expected an empty result, got %v, %v", domain, got, err)
+		}
+	}
+}
+
+func TestParseDomainAdversarial(t *testing.T) {
+	terms, err := ParseDomain(nestedAnyDomain(500))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for range 500 {
+		terms = terms[0].([]any)[2].([]any)
+	}
+	if !reflect.DeepEqual(terms, []any{[]any{"a", "=", 1}}) {
+		t.Errorf("unexpected innermost domain %v", terms)
+	}
+	if _, err := ParseDomain(connectorListDomain(1000)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := ParseDomain(nestedConnectorListDomain(1000)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestLexer(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token
+	}{
+		{"[('a','=',1)]", []token{
+			{typ: tokLBracket, pos: 0}, {typ: tokLParen, pos: 1}, {typ: tokString, str: "a", pos: 2},
+			{typ: tokComma, pos: 5}, {typ: tokString, str: "=", pos: 6}, {typ: tokComma, pos: 9},
+			{typ: tokInt, str: "1", pos: 10}, {typ: tokRParen, pos: 11}, {typ: tokRBracket, pos: 12},
+			{typ: tokEOF, pos: 13},
+		}},
+		// positions are byte offsets
+		{"'é' , -1.5", []token{
+			{typ: tokString, str: "é", pos: 0}, {typ: tokComma, pos: 5}, {typ: tokFloat, str: "-1.5", pos: 7},
+			{typ: tokEOF, pos: 11},
+		}},
+		{`'it\'s' "a\"b" 'c\\d' 'e\nf'`, []token{
+			{typ: tokString, str: "it's", pos: 0}, {typ: tokString, str: `a"b`, pos: 8},
+			{typ: tokString, str: `c\d`, pos: 15}, {typ: tokString, str: `e\nf`, pos: 22},
+			{typ: tokEOF, pos: 28},
+		}},
+		{"\u00a0True\u3000None false", []token{
+			{typ: tokTrue, str: "True", pos: 2}, {typ: tokNone, str: "None", pos: 9}, {typ: tokFalse, str: "false", pos: 14},
+			{typ: tokEOF, pos: 19},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := newLexer(tt.input).tokenize(nil)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.expected, got) {
+			t.Errorf("%s:\nexpected: %v\n     got: %v", tt.input, tt.expected, got)
+		}
+	}
+
+	for _, input := range []string{"'abc", `'abc\'`, "-", "1.", "Nope", "@"} {
+		if _, err := newLexer(input).tokenize(nil); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%s: expected ErrSyntax, got %v", input, err)
+		}
+	}
+}
+
 // --- Benchmarks ---
 
 func BenchmarkParseDomain_Empty(b *testing.B) {
@@ -178,8 +312,9 @@ func BenchmarkParseDomain_ValidationError(b *testing.B) {
 
 func BenchmarkLexer(b *testing.B) {
 	const domain = "[('name', 'like', 'John'),('ref', 'not like', 12345),('value','=',123.45),'|', ('is_company', '=', True),('customer','=',True)]"
+	var tokens []token
 	for b.Loop() {
-		newLexer(domain).tokenize()
+		tokens, _ = newLexer(domain).tokenize(tokens[:0])
 	}
 }
 
@@ -196,3 +331,41 @@ func BenchmarkValidateDomain(b *testing.B) {
 		ValidateDomain(terms)
 	}
 }
+
+// Adversarial inputs for the single-pass parser: parse time must grow
+// linearly with n.
+
+func nestedAnyDomain(n int) string {
+	return strings.Repeat("[('line_ids','any',", n) + "[('a','=',1)]" + strings.Repeat(")]", n)
+}
+
+func connectorListDomain(n int) string {
+	return "[('tag','in',[" + strings.Repeat("'|',", n) + "'x'])]"
+}
+
+func nestedConnectorListDomain(n int) string {
+	return "[('tag','in'," + strings.Repeat("['&',", n) + "1" + strings.Repeat("]", n) + ")]"
+}
+
+func benchmarkAdversarial(b *testing.B, build func(int) string) {
+	for _, n := range []int{10, 100, 1000} {
+		domain := build(n)
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				ParseDomain(domain)
+			}
+		})
+	}
+}
+
+func BenchmarkParseDomain_AdversarialNestedAny(b *testing.B) {
+	benchmarkAdversarial(b, nestedAnyDomain)
+}
+
+func BenchmarkParseDomain_AdversarialConnectorList(b *testing.B) {
+	benchmarkAdversarial(b, connectorListDomain)
+}
+
+func BenchmarkParseDomain_AdversarialNestedConnectorLists(b *testing.B) {
+	benchmarkAdversarial(b, nestedConnectorListDomain)
+}
@@ -0,0 +1,284 @@
+package odoosearchdomain
+
+import "strings"
+
+// RelationKind tells the path transforms how a relational field behaves.
+type RelationKind int
+
+const (
+	// RelationUnknown is used for relations missing from Relations. Only
+	// transforms that hold for both kinds are applied to them.
+	RelationUnknown RelationKind = iota
+	// RelationMany2one is a single-valued relation.
+	RelationMany2one
+	// RelationX2many is a one2many or many2many relation.
+	RelationX2many
+)
+
+// Relations maps relational field paths, from the domain's model, to their
+// kind, e.g. {"partner_id": RelationMany2one, "order_line": RelationX2many}.
+type Relations map[string]RelationKind
+
+// negativeOperators are the operators Odoo resolves through x2many paths as
+// "no related record matches the positive operator".
+var negativeOperators = map[string]bool{
+	"!=": true, "not like": true, "not ilike": true, "not in": true, "not any": true,
+}
+
+// LiftPaths rewrites dotted relational paths into explicit 'any' subdomains,
+// following Odoo 17 semantics:
+//
+//	('partner_id.country_id.code','=','BE')
+//	-> ('partner_id','any',[('country_id','any',[('code','=','BE')])])
+//
+// On x2many relations a negative operator becomes 'not any' with the
+// positive operator. Leaves with a negative operator on a relation of unknown
+// kind are left alone, since many2one and x2many paths disagree for them.
+//
+// Leaves sharing a relation are grouped under a single 'any' when that is
+// equivalent: always within '|', within '&' only for many2one relations
+// (two lines matching separately are not one line matching both). 'not any'
+// leaves on the same relation are grouped within '&'. Date granularity
+// suffixes are not relations and are kept.
+func LiftPaths(terms []any, relations Relations) ([]any, error) {
+	return liftDomain(terms, "", relations)
+}
+
+func liftDomain(terms []any, scope string, relations Relations) ([]any, error) {
+	tree, err := buildTree(terms)
+	if err != nil {
+		return []any{}, err
+	}
+	if tree == nil {
+		return []any{}, nil
+	}
+	lifted, err := liftNode(tree, scope, relations)
+	if err != nil {
+		return []any{}, err
+	}
+	return lifted.toDomain(), nil
+}
+
+func liftNode(n *node, scope string, relations Relations) (*node, error) {
+	switch n.op {
+	case "":
+		return liftNested(liftLeaf(n.leaf, scope, relations), scope, relations)
+	case "!":
+		child, err := liftNode(n.children[0], scope, relations)
+		if err != nil {
+			return nil, err
+		}
+		return newNot(child), nil
+	}
+
+	// Lift direct leaves by one level, group them, and only then lift
+	// their nested domains so grouped subdomains are lifted together.
+	children := make([]*node, 0, len(n.children))
+	for _, child := range n.children {
+		if child.isLeaf() {
+			children = append(children, liftLeaf(child.leaf, scope, relations))
+			continue
+		}
+		lifted, err := liftNode(child, scope, relations)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, lifted)
+	}
+	children, err := groupSubdomains(n.op, children, scope, relations)
+	if err != nil {
+		return nil, err
+	}
+	for i, child := range children {
+		if !child.isLeaf() {
+			continue
+		}
+		if children[i], err = liftNested(child, scope, relations); err != nil {
+			return nil, err
+		}
+	}
+	return newNary(n.op, children...), nil
+}
+
+// liftLeaf lifts the first relation of a dotted path into an 'any' or
+// 'not any' leaf, returning the leaf unchanged when that is not equivalent.
+func liftLeaf(term []any, scope string, relations Relations) *node {
+	field, isString := term[0].(string)
+	op, _ := term[1].(string)
+	if !isString || scalarPath(field) {
+		return newLeaf(term)
+	}
+	relation, rest, _ := strings.Cut(field, ".")
+
+	switch relations[joinScope(scope, relation)] {
+	case RelationX2many:
+		if negativeOperators[op] {
+			return newLeaf([]any{relation, "not any", []any{[]any{rest, termOperatorNegation[op], term[2]}}})
+		}
+	case RelationUnknown:
+		if negativeOperators[op] {
+			return newLeaf(term)
+		}
+	}
+	return newLeaf([]any{relation, "any", []any{[]any{rest, op, term[2]}}})
+}
+
+// liftNested lifts the nested domain of an 'any' or 'not any' leaf.
+func liftNested(n *node, scope string, relations Relations) (*node, error) {
+	nested, field, ok := nestedDomain(n.leaf)
+	if !ok {
+		return n, nil
+	}
+	lifted, err := liftDomain(nested, joinScope(scope, field), relations)
+	if err != nil {
+		return nil, err
+	}
+	return newLeaf([]any{field, n.leaf[1], lifted}), nil
+}
+
+// groupSubdomains merges 'any' leaves on the same relation within '|' (and
+// within '&' for many2one relations), and 'not any' leaves within '&'.
+func groupSubdomains(op string, children []*node, scope string, relations Relations) ([]*node, error) {
+	type group struct {
+		at      int
+		inner   string
+		domains [][]any
+	}
+	groups := map[[2]string]*group{}
+	var out []*node
+	for _, child := range children {
+		nested, field, ok := child.isLeafWithNested()
+		if !ok {
+			out = append(out, child)
+			continue
+		}
+		subOp := child.leaf[1].(string)
+		inner := ""
+		switch {
+		case subOp == "any" && (op == "|" || relations[joinScope(scope, field)] == RelationMany2one):
+			inner = op
+		case subOp == "not any" && op == "&":
+			inner = "|"
+		default:
+			out = append(out, child)
+			continue
+		}
+		key := [2]string{field, subOp}
+		g, seen := groups[key]
+		if !seen {
+			g = &group{at: len(out), inner: inner}
+			groups[key] = g
+			out = append(out, child)
+		}
+		g.domains = append(g.domains, nested)
+	}
+
+	for key, g := range groups {
+		if len(g.domains) < 2 {
+			continue
+		}
+		var trees []*node
+		always := false
+		for _, domain := range g.domains {
+			tree, err := buildTree(domain)
+			if err != nil {
+				return nil, err
+			}
+			if tree == nil {
+				// An empty domain is TRUE: it absorbs an OR and is
+				// neutral in an AND.
+				always = always || g.inner == "|"
+				continue
+			}
+			trees = append(trees, tree)
+		}
+		merged := []any{}
+		if !always && len(trees) > 0 {
+			merged = newNary(g.inner, trees...).toDomain()
+		}
+		out[g.at] = newLeaf([]any{key[0], key[1], merged})
+	}
+	return out, nil
+}
+
+func (n *node) isLeafWithNested() ([]any, string, bool) {
+	if !n.isLeaf() {
+		return nil, "", false
+	}
+	return nestedDomain(n.leaf)
+}
+
+// FlattenAny is the inverse of LiftPaths: it rewrites 'any' and 'not any'
+// leaves whose nested domain is a single leaf, or one connector over leaves,
+// into dotted paths when the result is equivalent:
+//
+//   - ('r','any',[leaf]) becomes ('r.field', op, value) for a positive
+//     operator, or for any operator when r is many2one;
+//   - ('r','not any',[leaf]) becomes ('r.field', negated op, value) when r
+//     is x2many and the leaf's operator is positive;
+//   - an OR of leaves under 'any', an AND of leaves under a many2one 'any'
+//     and an OR of leaves under an x2many 'not any' are split into one
+//     dotted leaf per operand.
+//
+// Nested domains are flattened bottom-up; anything else is kept.
+func FlattenAny(terms []any, relations Relations) ([]any, error) {
+	// Rewrite hands over each leaf after its nested domain was rewritten.
+	return Rewrite(terms, func(item WalkItem) ([]any, error) {
+		if _, field, ok := nestedDomain(item.Leaf); ok {
+			if flat, ok := flattenLeaf(item.Leaf, relations[joinScope(item.Scope, field)]); ok {
+				return flat, nil
+			}
+		}
+		return []any{item.Leaf}, nil
+	})
+}
+
+func flattenLeaf(leaf []any, kind RelationKind) ([]any, bool) {
+	relation := leaf[0].(string)
+	subOp := leaf[1].(string)
+	tree, err := buildTree(leaf[2].([]any))
+	if err != nil || tree == nil {
+		return nil, false
+	}
+
+	operands, connector := []*node{tree}, ""
+	if !tree.isLeaf() {
+		operands, connector = tree.children, tree.op
+		for _, operand := range operands {
+			if !operand.isLeaf() {
+				return nil, false
+			}
+		}
+	}
+	switch {
+	case connector == "":
+	case subOp == "any" && connector == "|":
+	case subOp == "any" && connector == "&" && kind == RelationMany2one:
+	case subOp == "not any" && connector == "|" && kind == RelationX2many:
+		connector = "&"
+	default:
+		return nil, false
+	}
+
+	flat := make([]*node, len(operands))
+	for i, operand := range operands {
+		field, isString := operand.leaf[0].(string)
+		op, _ := operand.leaf[1].(string)
+		if !isString {
+			return nil, false
+		}
+		path := relation + "." + field
+		switch {
+		case subOp == "any" && (kind == RelationMany2one || !negativeOperators[op]):
+			flat[i] = newLeaf([]any{path, op, operand.leaf[2]})
+		case subOp == "not any" && kind == RelationX2many && !negativeOperators[op] && termOperatorNegation[op] != "":
+			flat[i] = newLeaf([]any{path, termOperatorNegation[op], operand.leaf[2]})
+		default:
+			return nil, false
+		}
+	}
+	if connector == "" {
+		return flat[0].toDomain(), true
+	}
+	return newNary(connector, flat...).toDomain(), true
+}
@@ -0,0 +1,125 @@
+package odoosearchdomain
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testRelations = Relations{
+	"partner_id":            RelationMany2one,
+	"partner_id.country_id": RelationMany2one,
+	"order_line":            RelationX2many,
+	"order_line.product_id": RelationMany2one,
+}
+
+var liftPathsPatterns = []struct {
+	domain   string
+	expected []any
+}{
+	{"[('name','=','x')]", []any{[]any{"name", "=", "x"}}},
+	{"[('birthday.month_number','=',2)]", []any{[]any{"birthday.month_number", "=", 2}}},
+	{
+		"[('partner_id.country_id.code','=','BE')]",
+		[]any{[]any{"partner_id", "any", []any{[]any{"country_id", "any", []any{[]any{"code", "=", "BE"}}}}}},
+	},
+	{
+		"[('order_line.product_id.type','!=','service')]",
+		[]any{[]any{"order_line", "not any", []any{[]any{"product_id", "any", []any{[]any{"type", "=", "service"}}}}}},
+	},
+	// many2one negative operators stay 'any'; unknown relations are left alone
+	{
+		"[('partner_id.name','!=','x'),('user_id.name','!=','y')]",
+		[]any{"&", []any{"partner_id", "any", []any{[]any{"name", "!=", "x"}}}, []any{"user_id.name", "!=", "y"}},
+	},
+	{"[('user_id.name','=','y')]", []any{[]any{"user_id", "any", []any{[]any{"name", "=", "y"}}}}},
+	// many2one leaves are grouped within '&', and grouped subdomains are lifted together
+	{
+		"[('partner_id.name','=','x'),('partner_id.country_id.code','=','BE'),('partner_id.country_id.name','=','Belgium')]",
+		[]any{[]any{"partner_id", "any", []any{"&", []any{"name", "=", "x"},
+			[]any{"country_id", "any", []any{"&", []any{"code", "=", "BE"}, []any{"name", "=", "Belgium"}}}}}},
+	},
+	// x2many leaves are grouped within '|' only
+	{
+		"[('order_line.qty','>',1),('order_line.price','>',5)]",
+		[]any{"&", []any{"order_line", "any", []any{[]any{"qty", ">", 1}}}, []any{"order_line", "any", []any{[]any{"price", ">", 5}}}},
+	},
+	{
+		"['|',('order_line.qty','>',1),('order_line.price','>',5)]",
+		[]any{[]any{"order_line", "any", []any{"|", []any{"qty", ">", 1}, []any{"price", ">", 5}}}},
+	},
+	{
+		"[('order_line.qty','!=',1),('order_line.price','!=',5)]",
+		[]any{[]any{"order_line", "not any", []any{"|", []any{"qty", "=", 1}, []any{"price", "=", 5}}}},
+	},
+}
+
+func TestLiftPaths(t *testing.T) {
+	for i, pattern := range liftPathsPatterns {
+		terms, err := ParseDomain(pattern.domain)
+		if err != nil {
+			t.Fatalf("[%d] parse %s: %v", i, pattern.domain, err)
+		}
+		got, err := LiftPaths(terms, testRelations)
+		if err != nil {
+			t.Errorf("[%d] unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(pattern.expected, got) {
+			t.Errorf("[%d] %s\nexpected: %v\n     got: %v", i, pattern.domain, pattern.expected, got)
+		}
+	}
+}
+
+var flattenAnyPatterns = []struct {
+	domain   string
+	expected []any
+}{
+	{"[('partner_id','any',[('name','=','x')])]", []any{[]any{"partner_id.name", "=", "x"}}},
+	{"[('partner_id','any',[('name','!=','x')])]", []any{[]any{"partner_id.name", "!=", "x"}}},
+	{"[('order_line','any',[('qty','>',1)])]", []any{[]any{"order_line.qty", ">", 1}}},
+	{"[('order_line','any',[('qty','!=',1)])]", []any{[]any{"order_line", "any", []any{[]any{"qty", "!=", 1}}}}},
+	{"[('order_line','not any',[('qty','=',1)])]", []any{[]any{"order_line.qty", "!=", 1}}},
+	{"[('user_ids','not any',[('name','=','x')])]", []any{[]any{"user_ids", "not any", []any{[]any{"name", "=", "x"}}}}},
+	// nested subdomains flatten bottom-up
+	{
+		"[('order_line','any',[('product_id','any',[('type','=','service')])])]",
+		[]any{[]any{"order_line.product_id.type", "=", "service"}},
+	},
+	{
+		"[('partner_id','any',[('name','=','x'),('email','=','y')])]",
+		[]any{"&", []any{"partner_id.name", "=", "x"}, []any{"partner_id.email", "=", "y"}},
+	},
+	{
+		"[('order_line','any',[('qty','>',1),('price','>',5)])]",
+		[]any{[]any{"order_line", "any", []any{[]any{"qty", ">", 1}, []any{"price", ">", 5}}}},
+	},
+	{
+		"[('order_line','any',['|',('qty','>',1),('price','>',5)])]",
+		[]any{"|", []any{"order_line.qty", ">", 1}, []any{"order_line.price", ">", 5}},
+	},
+	{"[('order_line','any',[])]", []any{[]any{"order_line", "any", []any{}}}},
+}
+
+func TestFlattenAny(t *testing.T) {
+	for i, pattern := range flattenAnyPatterns {
+		terms, err := ParseDomain(pattern.domain)
+		if err != nil {
+			t.Fatalf("[%d] parse %s: %v", i, pattern.domain, err)
+		}
+		got, err := FlattenAny(terms, testRelations)
+		if err != nil {
+			t.Errorf("[%d] unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(pattern.expected, got) {
+			t.Errorf("[%d] %s\nexpected: %v\n     got: %v", i, pattern.domain, pattern.expected, got)
+		}
+	}
+}
+
+func TestLiftFlattenRoundTrip(t *testing.T) {
+	terms, _ := ParseDomain("['|',('order_line.product_id.type','=','service'),('partner_id.country_id.code','=','BE')]")
+	lifted, _ := LiftPaths(terms, testRelations)
+	flat, _ := FlattenAny(lifted, testRelations)
+	if !reflect.DeepEqual(terms, flat) {
+		t.Errorf("round trip changed the domain\nexpected: %v\n     got: %v", terms, flat)
+	}
+}
@@ -0,0 +1,352 @@
+package odoosearchdomain
+
+import "time"
+
+// Interval is the set of values a field is restricted to by the comparison
+// leaves of a conjunction. A nil bound is unbounded.
+type Interval struct {
+	Lower          any
+	LowerInclusive bool
+	Upper          any
+	UpperInclusive bool
+	// Empty is true when the bounds exclude every value.
+	Empty bool
+}
+
+// restrict narrows the interval by one leaf. It returns false, leaving the
+// interval untouched, when the value cannot be ordered against the current
+// bounds.
+func (iv *Interval) restrict(op string, value any) bool {
+	lower, upper := iv.Lower, iv.Upper
+	lowerIncl, upperIncl := iv.LowerInclusive, iv.UpperInclusive
+	tighten := func(bound any, inclusive bool, isLower bool) bool {
+		current, currentIncl := upper, upperIncl
+		dir := -1
+		if isLower {
+			current, currentIncl, dir = lower, lowerIncl, 1
+		}
+		if current != nil {
+			c, ok := compareRangeValues(bound, current)
+			if !ok {
+				return false
+			}
+			if c*dir < 0 || (c == 0 && (inclusive || !currentIncl)) {
+				return true
+			}
+		}
+		if isLower {
+			lower, lowerIncl = bound, inclusive
+		} else {
+			upper, upperIncl = bound, inclusive
+		}
+		return true
+	}
+
+	if _, ok := rangeValue(value); !ok {
+		return false
+	}
+	var ok bool
+	switch op {
+	case ">":
+		ok = tighten(value, false, true)
+	case ">=":
+		ok = tighten(value, true, true)
+	case "<":
+		ok = tighten(value, false, false)
+	case "<=":
+		ok = tighten(value, true, false)
+	case "=":
+		ok = tighten(value, true, true) && tighten(value, true, false)
+	}
+	if !ok {
+		return false
+	}
+
+	empty := iv.Empty
+	if lower != nil && upper != nil {
+		c, comparable := compareRangeValues(lower, upper)
+		if !comparable {
+			return false
+		}
+		empty = empty || c > 0 || (c == 0 && !(lowerIncl && upperIncl))
+	}
+	iv.Lower, iv.LowerInclusive = lower, lowerIncl
+	iv.Upper, iv.UpperInclusive = upper, upperIncl
+	iv.Empty = empty
+	return true
+}
+
+// leaves renders the interval as the smallest equivalent set of leaves.
+func (iv Interval) leaves(field string) []*node {
+	if iv.Empty {
+		return []*node{newLeaf(falseLeaf())}
+	}
+	if iv.Lower != nil && iv.Upper != nil && iv.LowerInclusive && iv.UpperInclusive {
+		if c, _ := compareRangeValues(iv.Lower, iv.Upper); c == 0 {
+			return []*node{newLeaf([]any{field, "=", iv.Lower})}
+		}
+	}
+	var out []*node
+	if iv.Lower != nil {
+		op := ">"
+		if iv.LowerInclusive {
+			op = ">="
+		}
+		out = append(out, newLeaf([]any{field, op, iv.Lower}))
+	}
+	if iv.Upper != nil {
+		op := "<"
+		if iv.UpperInclusive {
+			op = "<="
+		}
+		out = append(out, newLeaf([]any{field, op, iv.Upper}))
+	}
+	return out
+}
+
+// FieldRanges computes, for every field constrained by '=', '<', '<=', '>'
+// or '>=' leaves of the domain's top-level conjunction, the tightest interval
+// those leaves allow. Values may be numbers, ISO dates ('2024-01-31') or ISO
+// datetimes ('2024-01-31 12:00:00'), ordered chronologically; a date is not
+// ordered against a datetime. Other strings are left alone, since the
+// database orders them by its collation. Leaves under '|' or '!',
+// relational paths and values that cannot be ordered against each other are
+// not taken into account.
+func FieldRanges(terms []any) (map[string]Interval, error) {
+	tree, err := buildTree(terms)
+	if err != nil {
+		return nil, err
+	}
+	ranges := map[string]Interval{}
+	if tree == nil {
+		return ranges, nil
+	}
+	conj := []*node{tree}
+	if tree.op == "&" {
+		conj = tree.children
+	}
+	for _, child := range conj {
+		field, op, value, ok := rangeLeaf(child)
+		if !ok {
+			continue
+		}
+		iv := ranges[field]
+		if iv.restrict(op, value) {
+			ranges[field] = iv
+		}
+	}
+	return ranges, nil
+}
+
+// MergeRanges simplifies a domain by collapsing the comparison leaves each
+// conjunction places on the same field into at most two leaves (or a single
+// '=' leaf when the bounds meet). A conjunction whose bounds exclude every
+// value is replaced by Odoo's FALSE_LEAF, (0, '=', 1), which in turn is
+// dropped from disjunctions. Nested 'any' and 'not any' domains are
+// simplified as well. See FieldRanges for the values that are understood.
+func MergeRanges(terms []any) ([]any, error) {
+	tree, err := buildTree(terms)
+	if err != nil {
+		return []any{}, err
+	}
+	if tree == nil {
+		return []any{}, nil
+	}
+	merged, err := mergeRangesNode(tree)
+	if err != nil {
+		return []any{}, err
+	}
+	return merged.toDomain(), nil
+}
+
+func mergeRangesNode(n *node) (*node, error) {
+	switch n.op {
+	case "":
+		op, _ := n.leaf[1].(string)
+		nested, isList := n.leaf[2].([]any)
+		if (op == "any" || op == "not any") && isList {
+			merged, err := MergeRanges(nested)
+			if err != nil {
+				return nil, err
+			}
+			return newLeaf([]any{n.leaf[0], op, merged}), nil
+		}
+		return n, nil
+	case "!":
+		child, err := mergeRangesNode(n.children[0])
+		if err != nil {
+			return nil, err
+		}
+		return newNot(child), nil
+	}
+
+	children := make([]*node, 0, len(n.children))
+	for _, child := range n.children {
+		merged, err := mergeRangesNode(child)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, merged)
+	}
+	if n.op == "|" {
+		kept := children[:0]
+		for _, child := range children {
+			if !child.isFalseLeaf() {
+				kept = append(kept, child)
+			}
+		}
+		if len(kept) == 0 {
+			return newLeaf(falseLeaf()), nil
+		}
+		return newNary("|", kept...), nil
+	}
+
+	// Collect the interval of every field; the merged bounds then take the
+	// place of the field's first comparison leaf.
+	intervals := map[string]*Interval{}
+	counts := map[string]int{}
+	for _, child := range children {
+		if child.isFalseLeaf() {
+			return child, nil
+		}
+		field, op, value, ok := rangeLeaf(child)
+		if !ok {
+			continue
+		}
+		iv, seen := intervals[field]
+		if seen && iv == nil {
+			continue
+		}
+		if !seen {
+			iv = &Interval{}
+			intervals[field] = iv
+		}
+		if !iv.restrict(op, value) {
+			// Incomparable values: leave this field alone.
+			intervals[field] = nil
+		}
+		counts[field]++
+	}
+
+	out := make([]*node, 0, len(children))
+	emitted := map[string]bool{}
+	for _, child := range children {
+		field, _, _, ok := rangeLeaf(child)
+		iv := intervals[field]
+		if !ok || iv == nil || (counts[field] < 2 && !iv.Empty) {
+			out = append(out, child)
+			continue
+		}
+		if iv.Empty {
+			return newLeaf(falseLeaf()), nil
+		}
+		if !emitted[field] {
+			emitted[field] = true
+			out = append(out, iv.leaves(field)...)
+		}
+	}
+	return newNary("&", out...), nil
+}
+
+// rangeLeaf extracts the parts of a comparison leaf on a scalar field.
+func rangeLeaf(n *node) (field, op string, value any, ok bool) {
+	if n.op != "" {
+		return "", "", nil, false
+	}
+	field, isString := n.leaf[0].(string)
+	op, _ = n.leaf[1].(string)
+	switch op {
+	case "=", "<", "<=", ">", ">=":
+	default:
+		return "", "", nil, false
+	}
+	if !isString || !scalarPath(field) {
+		return "", "", nil, false
+	}
+	if _, orderable := rangeValue(n.leaf[2]); !orderable {
+		return "", "", nil, false
+	}
+	return field, op, n.leaf[2], true
+}
+
+// rangeValue normalizes a value that can take part in an ordering: a number
+// or an ISO date or datetime.
+func rangeValue(v any) (any, bool) {
+	n, ok := normalizeScalar(v)
+	if !ok {
+		return nil, false
+	}
+	switch scalarKind(n) {
+	case "number", "date", "datetime":
+		return n, true
+	}
+	return nil, false
+}
+
+func compareRangeValues(a, b any) (int, bool) {
+	x, ok := rangeValue(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := rangeValue(b)
+	if !ok {
+		return 0, false
+	}
+	return compareScalars(x, y)
+}
+
+// falseLeaf returns Odoo's FALSE_LEAF, a term that never matches.
+func falseLeaf() []any {
+	return []any{0, "=", 1}
+}
+
+func (n *node) isFalseLeaf() bool {
+	if n.op != "" {
+		return false
+	}
+	zero, ok1 := n.leaf[0].(int)
+	op, ok2 := n.leaf[1].(string)
+	one, ok3 := n.leaf[2].(int)
+	return ok1 && ok2 && ok3 && zero == 0 && op == "=" && one == 1
+}
+
+// dateLayouts are the string formats Odoo uses for date and datetime values.
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999",
+}
+
+// parseDateValue parses an ISO date or datetime string as a UTC time.
+func parseDateValue(s string) (time.Time, bool) {
+	if len(s) < len("2006-01-02") || s[4] != '-' || s[7] != '-' {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compareStrings orders two ISO dates or two ISO datetimes chronologically.
+// Other strings are not ordered, ok being false: the database sorts them by
+// its collation, which bytewise order does not reproduce. Neither is a date
+// ordered against a datetime, since on a datetime field a date bound stands
+// for the start or the end of its day depending on the operator.
+func compareStrings(a, b string) (int, bool) {
+	ta, aOK := parseDateValue(a)
+	tb, bOK := parseDateValue(b)
+	if !aOK || !bOK || isDateString(a) != isDateString(b) {
+		return 0, false
+	}
+	return ta.Compare(tb), true
+}
+
+// isDateString reports whether an ISO date or datetime string is a date.
+func isDateString(s string) bool {
+	return len(s) == len("2006-01-02")
+}
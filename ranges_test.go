@@ -0,0 +1,103 @@
+package odoosearchdomain
+
+import (
+	"reflect"
+	"testing"
+)
+
+var mergeRangesPatterns = []struct {
+	domain   string
+	expected []any
+}{
+	{"[]", []any{}},
+	{"[('qty','>',1)]", []any{[]any{"qty", ">", 1}}},
+	{
+		"[('date','>=','2024-01-01'),('date','<','2024-02-01'),('date','>=','2024-01-15')]",
+		[]any{"&", []any{"date", ">=", "2024-01-15"}, []any{"date", "<", "2024-02-01"}},
+	},
+	{
+		"[('qty','>',1),('name','=','x'),('qty','>=',5),('qty','<=',10),('qty','<',20)]",
+		[]any{"&", "&", []any{"qty", ">=", 5}, []any{"qty", "<=", 10}, []any{"name", "=", "x"}},
+	},
+	{"[('qty','>=',5),('qty','<=',5)]", []any{[]any{"qty", "=", 5}}},
+	{"[('qty','>',5),('qty','<=',5),('name','=','x')]", []any{[]any{0, "=", 1}}},
+	{"[('qty','=',3),('qty','>',5)]", []any{[]any{0, "=", 1}}},
+	// dates and datetimes are ordered chronologically
+	{
+		"[('date','>=','2024-01-15 08:00:00'),('date','>','2024-01-15 06:00:00')]",
+		[]any{[]any{"date", ">=", "2024-01-15 08:00:00"}},
+	},
+	// a date bound covers its whole day on a datetime field, so it is not
+	// merged with datetime bounds
+	{
+		"[('date','>=','2024-01-15 08:00:00'),('date','>','2024-01-15')]",
+		[]any{"&", []any{"date", ">=", "2024-01-15 08:00:00"}, []any{"date", ">", "2024-01-15"}},
+	},
+	{
+		"[('d','<=','2024-01-31'),('d','<','2024-01-31 12:00:00')]",
+		[]any{"&", []any{"d", "<=", "2024-01-31"}, []any{"d", "<", "2024-01-31 12:00:00"}},
+	},
+	{
+		"[('d','>','2024-01-31'),('d','>=','2024-01-31 12:00:00')]",
+		[]any{"&", []any{"d", ">", "2024-01-31"}, []any{"d", ">=", "2024-01-31 12:00:00"}},
+	},
+	// ordinary strings follow the database collation and are left alone
+	{
+		"[('name','>','a'),('name','<','B')]",
+		[]any{"&", []any{"name", ">", "a"}, []any{"name", "<", "B"}},
+	},
+	{
+		"[('name','>=','m'),('name','>=','a'),('qty','>',1),('qty','>',2)]",
+		[]any{"&", "&", []any{"name", ">=", "m"}, []any{"name", ">=", "a"}, []any{"qty", ">", 2}},
+	},
+	// empty disjuncts disappear
+	{
+		"['|','&',('qty','>',5),('qty','<',1),('name','=','x')]",
+		[]any{[]any{"name", "=", "x"}},
+	},
+	// leaves under '|' are not merged across the disjunction
+	{
+		"['|',('qty','>',5),('qty','>',1)]",
+		[]any{"|", []any{"qty", ">", 5}, []any{"qty", ">", 1}},
+	},
+	// relational paths may be multi-valued and are left alone
+	{
+		"[('line_ids.qty','>',5),('line_ids.qty','<',1)]",
+		[]any{"&", []any{"line_ids.qty", ">", 5}, []any{"line_ids.qty", "<", 1}},
+	},
+	{
+		"[('line_ids','any',[('qty','>',1),('qty','>',3)])]",
+		[]any{[]any{"line_ids", "any", []any{[]any{"qty", ">", 3}}}},
+	},
+}
+
+func TestMergeRanges(t *testing.T) {
+	for i, pattern := range mergeRangesPatterns {
+		terms, err := ParseDomain(pattern.domain)
+		if err != nil {
+			t.Fatalf("[%d] parse %s: %v", i, pattern.domain, err)
+		}
+		got, err := MergeRanges(terms)
+		if err != nil {
+			t.Errorf("[%d] unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(pattern.expected, got) {
+			t.Errorf("[%d] %s\nexpected: %v\n     got: %v", i, pattern.domain, pattern.expected, got)
+		}
+	}
+}
+
+func TestFieldRanges(t *testing.T) {
+	terms, _ := ParseDomain("[('date','>=','2024-01-01'),('date','<','2024-02-01'),('date','>=','2024-01-15'),('qty','>',5),('qty','<',2),'|',('x','>',1),('x','<',0)]")
+	got, err := FieldRanges(terms)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := map[string]Interval{
+		"date": {Lower: "2024-01-15", LowerInclusive: true, Upper: "2024-02-01"},
+		"qty":  {Lower: 5, Upper: 2, Empty: true},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected: %v\n     got: %v", expected, got)
+	}
+}
@@ -0,0 +1,67 @@
+package odoosearchdomain
+
+import (
+	"slices"
+	"strings"
+)
+
+// FieldReference describes a field path used by a domain.
+type FieldReference struct {
+	// Path is the field path from the domain's model, including the scope
+	// of enclosing 'any' leaves and without a date granularity suffix.
+	Path string
+	// Segments is Path split on '.', one segment per field traversed.
+	Segments []string
+	// Granularity is the date part suffix ('month_number', ...), if any.
+	Granularity string
+	// Scope is the relation path of the 'any'/'not any' leaves the field
+	// was used under, or empty at the top level.
+	Scope string
+	// Operators lists the operators used with the field, in order of first
+	// appearance.
+	Operators []string
+}
+
+// ReferencedFields returns every field path a domain uses, including the
+// relational fields of 'any' and 'not any' leaves and the fields of their
+// nested domains, in order of first appearance. Uses of the same path under
+// the same scope and granularity are merged.
+func ReferencedFields(terms []any) ([]FieldReference, error) {
+	var refs []FieldReference
+	index := map[[3]string]int{}
+	err := Walk(terms, func(item WalkItem) error {
+		if item.Connector != "" {
+			return nil
+		}
+		field, ok := item.Leaf[0].(string)
+		if !ok {
+			return nil
+		}
+		op, _ := item.Leaf[1].(string)
+		path, granularity := joinScope(item.Scope, field), ""
+		if base, suffix := splitLastSegment(field); base != "" && dateGranularities[suffix] {
+			path, granularity = joinScope(item.Scope, base), suffix
+		}
+
+		key := [3]string{path, granularity, item.Scope}
+		i, seen := index[key]
+		if !seen {
+			i = len(refs)
+			index[key] = i
+			refs = append(refs, FieldReference{
+				Path:        path,
+				Segments:    strings.Split(path, "."),
+				Granularity: granularity,
+				Scope:       item.Scope,
+			})
+		}
+		if !slices.Contains(refs[i].Operators, op) {
+			refs[i].Operators = append(refs[i].Operators, op)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
@@ -0,0 +1,26 @@
+package odoosearchdomain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReferencedFields(t *testing.T) {
+	terms, _ := ParseDomain("[('name','ilike','a'),'|',('partner_id.country_id.code','=','BE'),('name','=','b'),('birthday.month_number','in',[1,2]),('order_line','any',[('product_id.type','=','service'),('create_date.year_number','=',2024)]),('order_line.product_id.type','!=','consu')]")
+	got, err := ReferencedFields(terms)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []FieldReference{
+		{Path: "name", Segments: []string{"name"}, Operators: []string{"ilike", "="}},
+		{Path: "partner_id.country_id.code", Segments: []string{"partner_id", "country_id", "code"}, Operators: []string{"="}},
+		{Path: "birthday", Segments: []string{"birthday"}, Granularity: "month_number", Operators: []string{"in"}},
+		{Path: "order_line", Segments: []string{"order_line"}, Operators: []string{"any"}},
+		{Path: "order_line.product_id.type", Segments: []string{"order_line", "product_id", "type"}, Scope: "order_line", Operators: []string{"="}},
+		{Path: "order_line.create_date", Segments: []string{"order_line", "create_date"}, Granularity: "year_number", Scope: "order_line", Operators: []string{"="}},
+		{Path: "order_line.product_id.type", Segments: []string{"order_line", "product_id", "type"}, Operators: []string{"!="}},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, got)
+	}
+}
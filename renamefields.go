@@ -0,0 +1,95 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidMapping is returned for a field mapping that cannot be applied.
+var ErrInvalidMapping = errors.New("invalid field mapping")
+
+// FieldRename records one substitution made by RenameFields.
+type FieldRename struct {
+	// Path locates the leaf, as in WalkItem.Path.
+	Path []int
+	// Scope is the dotted path of the 'any'/'not any' leaves the leaf is
+	// nested under (before renaming).
+	Scope string
+	// Old and New are the leaf's field before and after renaming.
+	Old, New string
+}
+
+// RenameFields renames fields throughout a domain and reports every
+// substitution made.
+//
+// Mapping keys are field paths from the domain's model, so 'x_studio_ref'
+// renames a field of the model itself while 'partner_id.x_old' renames a
+// field of the partner. Values give the new name of the last segment, either
+// bare ('x_new') or as a path with the same parent ('partner_id.x_new').
+//
+// Paths are matched segment by segment, so renaming 'partner_id' also
+// rewrites 'partner_id.name' and a key matching a prefix of a longer path
+// renames that segment in place ('partner_id.x_old.name'). Leaves of nested
+// 'any' and 'not any' domains are matched by their full path, and date
+// granularity suffixes ('birthday.month_number') are never renamed.
+func RenameFields(terms []any, mapping map[string]string) ([]any, []FieldRename, error) {
+	names := make(map[string]string, len(mapping))
+	for key, value := range mapping {
+		parent, _ := splitLastSegment(key)
+		newParent, name := splitLastSegment(value)
+		if key == "" || name == "" || (strings.Contains(value, ".") && newParent != parent) {
+			return []any{}, nil, fmt.Errorf("%w: %q -> %q", ErrInvalidMapping, key, value)
+		}
+		names[key] = name
+	}
+
+	var renames []FieldRename
+	out, err := Rewrite(terms, func(item WalkItem) ([]any, error) {
+		field, ok := item.Leaf[0].(string)
+		if !ok {
+			return []any{item.Leaf}, nil
+		}
+		renamed := renamePath(item.Scope, field, names)
+		if renamed == field {
+			return []any{item.Leaf}, nil
+		}
+		renames = append(renames, FieldRename{Path: item.Path, Scope: item.Scope, Old: field, New: renamed})
+		return []any{[]any{renamed, item.Leaf[1], item.Leaf[2]}}, nil
+	})
+	if err != nil {
+		return []any{}, nil, err
+	}
+	// Rewrite reaches nested leaves before the leaf holding them.
+	slices.SortStableFunc(renames, func(a, b FieldRename) int {
+		return slices.Compare(a.Path, b.Path)
+	})
+	return out, renames, nil
+}
+
+// renamePath renames the segments of field, which lives under scope, using
+// names keyed by full path. Scope segments belong to enclosing leaves and
+// are left alone.
+func renamePath(scope, field string, names map[string]string) string {
+	segments := strings.Split(field, ".")
+	prefix := scope
+	for i, segment := range segments {
+		prefix = joinScope(prefix, segment)
+		if i > 0 && i == len(segments)-1 && dateGranularities[segment] {
+			break
+		}
+		if name, ok := names[prefix]; ok {
+			segments[i] = name
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+func splitLastSegment(path string) (parent, name string) {
+	i := strings.LastIndexByte(path, '.')
+	if i < 0 {
+		return "", path
+	}
+	return path[:i], path[i+1:]
+}
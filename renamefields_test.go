@@ -0,0 +1,67 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRenameFields(t *testing.T) {
+	terms, _ := ParseDomain("[('x_studio_ref','=','A'),'|',('partner_id.x_old.name','ilike','b'),('x_date.month_number','=',2),('order_line','any',[('x_studio_ref','=',1),('product_id','any',[('x_old','=',True)])]),('x_studio_ref.name','=','c')]")
+	mapping := map[string]string{
+		"x_studio_ref":                  "x_customer_ref",
+		"partner_id.x_old":              "partner_id.x_new",
+		"x_date":                        "x_order_date",
+		"order_line.x_studio_ref":       "x_line_ref",
+		"order_line.product_id":         "product_tmpl_id",
+		"order_line.product_id.x_old":   "x_new",
+		"x_date.month_number":           "x_month",
+		"partner_id.x_old.name.missing": "unused",
+	}
+	got, renames, err := RenameFields(terms, mapping)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []any{
+		[]any{"x_customer_ref", "=", "A"},
+		"|",
+		[]any{"partner_id.x_new.name", "ilike", "b"},
+		[]any{"x_order_date.month_number", "=", 2},
+		[]any{"order_line", "any", []any{
+			[]any{"x_line_ref", "=", 1},
+			[]any{"product_tmpl_id", "any", []any{[]any{"x_new", "=", true}}},
+		}},
+		[]any{"x_customer_ref.name", "=", "c"},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected: %v\n     got: %v", expected, got)
+	}
+	expectedRenames := []FieldRename{
+		{Path: []int{0}, Old: "x_studio_ref", New: "x_customer_ref"},
+		{Path: []int{2}, Old: "partner_id.x_old.name", New: "partner_id.x_new.name"},
+		{Path: []int{3}, Old: "x_date.month_number", New: "x_order_date.month_number"},
+		{Path: []int{4, 2, 0}, Scope: "order_line", Old: "x_studio_ref", New: "x_line_ref"},
+		{Path: []int{4, 2, 1}, Scope: "order_line", Old: "product_id", New: "product_tmpl_id"},
+		{Path: []int{4, 2, 1, 2, 0}, Scope: "order_line.product_id", Old: "x_old", New: "x_new"},
+		{Path: []int{5}, Old: "x_studio_ref.name", New: "x_customer_ref.name"},
+	}
+	if !reflect.DeepEqual(expectedRenames, renames) {
+		t.Errorf("expected renames:\n%v\ngot:\n%v", expectedRenames, renames)
+	}
+	// the input is left untouched
+	if terms[0].([]any)[0] != "x_studio_ref" {
+		t.Errorf("input domain was modified")
+	}
+}
+
+func TestRenameFieldsInvalidMapping(t *testing.T) {
+	for _, mapping := range []map[string]string{
+		{"partner_id.x_old": "company_id.x_new"},
+		{"x_old": ""},
+		{"": "x_new"},
+	} {
+		if _, _, err := RenameFields([]any{leafA}, mapping); !errors.Is(err, ErrInvalidMapping) {
+			t.Errorf("%v: expected ErrInvalidMapping, got %v", mapping, err)
+		}
+	}
+}
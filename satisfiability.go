@@ -0,0 +1,570 @@
+package odoosearchdomain
+
+import "strings"
+
+// Satisfiability is the result of CheckSatisfiability.
+type Satisfiability struct {
+	// Unsatisfiable is true when no record can ever match the domain.
+	Unsatisfiable bool
+	// Tautology is true when every record matches the domain.
+	Tautology bool
+	// Conflicts holds, for an unsatisfiable domain, one minimal set of
+	// mutually exclusive leaves per disjunct of the domain's DNF. Each set is
+	// itself a domain (its elements are implicitly ANDed). Negated leaves
+	// appear as Odoo reads them, e.g. ('x','<',5) for ['!',('x','>=',5)].
+	Conflicts [][]any
+}
+
+// CheckSatisfiability decides whether a domain can never match
+// (unsatisfiable) or always matches (tautology).
+//
+// The analysis understands '=', '!=', 'in' and 'not in' on scalar values
+// and '<', '<=', '>' and '>=' on numbers and ISO dates or datetimes, with
+// Odoo's convention that False and None stand for an unset value. Other
+// operators, ordered comparisons of other strings (the database sorts them
+// by its collation), fields compared against values of different kinds such
+// as '1' and 1, relational paths (which may traverse x2many fields) and
+// non-scalar values are treated conservatively: they never cause a domain to
+// be reported as unsatisfiable or tautological. A false result therefore
+// means "not proven", not "satisfiable".
+func CheckSatisfiability(terms []any) (Satisfiability, error) {
+	var result Satisfiability
+	tree, err := buildTree(terms)
+	if err != nil {
+		return result, err
+	}
+	if tree == nil {
+		result.Tautology = true
+		return result, nil
+	}
+
+	tree = tree.resolveNegations()
+	conjunctions, err := distribute(tree.nnf(), "|", DefaultNormalFormLimit)
+	if err != nil {
+		return result, err
+	}
+	result.Unsatisfiable = true
+	for _, conj := range conjunctions {
+		conflict := conjunctionConflict(conj)
+		if conflict == nil {
+			result.Unsatisfiable = false
+			result.Conflicts = nil
+			break
+		}
+		result.Conflicts = append(result.Conflicts, conflict)
+	}
+
+	negated, err := distribute(tree.negate(), "|", DefaultNormalFormLimit)
+	if err != nil {
+		return result, err
+	}
+	result.Tautology = true
+	for _, conj := range negated {
+		if conjunctionStatus(conj) != statusUnsat {
+			result.Tautology = false
+			break
+		}
+	}
+	return result, nil
+}
+
+// resolveNegations returns n with every '!' of the domain distributed to the
+// leaves as Odoo does before building its query: operators with an exact
+// complement are flipped, range operators are replaced by their inverse and
+// any other leaf L becomes L's complement restricted to set values, which is
+// what SQL NOT means. A '!' in the result is therefore an exact complement,
+// which negate can in turn build on when looking for counterexamples.
+func (n *node) resolveNegations() *node {
+	switch n.op {
+	case "":
+		return n
+	case "!":
+		return n.children[0].resolvedNegation()
+	default:
+		children := make([]*node, len(n.children))
+		for i, child := range n.children {
+			children[i] = child.resolveNegations()
+		}
+		return newNary(n.op, children...)
+	}
+}
+
+// resolvedNegation returns the domain ['!', n] with its negation resolved.
+func (n *node) resolvedNegation() *node {
+	switch n.op {
+	case "":
+		op, _ := n.leaf[1].(string)
+		if neg, ok := termOperatorNegation[op]; ok {
+			return newLeaf([]any{n.leaf[0], neg, n.leaf[2]})
+		}
+		if neg, ok := rangeOperatorNegation[op]; ok {
+			return newLeaf([]any{n.leaf[0], neg, n.leaf[2]})
+		}
+		return newNary("&", newNot(n), newLeaf([]any{n.leaf[0], "!=", false}))
+	case "!":
+		return n.children[0].resolveNegations()
+	default:
+		dual := "|"
+		if n.op == "|" {
+			dual = "&"
+		}
+		children := make([]*node, len(n.children))
+		for i, child := range n.children {
+			children[i] = child.resolvedNegation()
+		}
+		return newNary(dual, children...)
+	}
+}
+
+// ============================================================
+// Literal solver — per-field reasoning over a conjunction
+// ============================================================
+
+type satStatus int
+
+const (
+	statusUnknown satStatus = iota
+	statusSat
+	statusUnsat
+)
+
+// literal is a leaf, possibly negated, that the solver understands.
+type literal struct {
+	node    *node
+	field   string
+	op      string
+	value   any // normalized scalar, or []any of normalized scalars for in/not in
+	negated bool
+}
+
+// dateGranularities lists the date part suffixes Odoo accepts on date and
+// datetime fields, e.g. 'birthday.month_number'.
+var dateGranularities = map[string]bool{
+	"year_number": true, "quarter_number": true, "month_number": true,
+	"iso_week_number": true, "day_of_week": true, "day_of_month": true,
+	"day_of_year": true, "hour_number": true, "minute_number": true,
+	"second_number": true,
+}
+
+// scalarPath reports whether field names a value of the record itself
+// rather than a path through a relation that may be multi-valued.
+func scalarPath(field string) bool {
+	base, suffix, dotted := strings.Cut(field, ".")
+	if !dotted {
+		return true
+	}
+	return !strings.Contains(base, ".") && dateGranularities[suffix]
+}
+
+// toLiteral converts a leaf or negated leaf into a literal. It returns false
+// for anything the solver does not understand.
+func toLiteral(n *node) (literal, bool) {
+	lit := literal{node: n}
+	if n.op == "!" {
+		lit.negated = true
+		n = n.children[0]
+	}
+	field, ok := n.leaf[0].(string)
+	if !ok || !scalarPath(field) {
+		return lit, false
+	}
+	op, _ := n.leaf[1].(string)
+	lit.field, lit.op = field, op
+
+	switch op {
+	case "=", "!=":
+		v, ok := normalizeScalar(n.leaf[2])
+		if !ok {
+			return lit, false
+		}
+		lit.value = v
+	case "in", "not in":
+		values, isList := n.leaf[2].([]any)
+		if !isList {
+			values = []any{n.leaf[2]}
+		}
+		normalized := make([]any, len(values))
+		for i, value := range values {
+			v, ok := normalizeScalar(value)
+			if !ok {
+				return lit, false
+			}
+			normalized[i] = v
+		}
+		lit.value = normalized
+	case "<", "<=", ">", ">=":
+		v, ok := normalizeScalar(n.leaf[2])
+		if kind := scalarKind(v); !ok || kind == "" || kind == "text" {
+			return lit, false
+		}
+		lit.value = v
+	default:
+		return lit, false
+	}
+	return lit, true
+}
+
+// normalizeScalar maps domain values onto a small set of comparable
+// representations: float64 for numbers, string, true, and nil for the
+// unset value (None or False).
+func normalizeScalar(v any) (any, bool) {
+	switch t := v.(type) {
+	case nil:
+		return nil, true
+	case bool:
+		if !t {
+			return nil, true
+		}
+		return true, true
+	case string:
+		return t, true
+	case int:
+		return float64(t), true
+	case int32:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case float32:
+		return float64(t), true
+	case float64:
+		return t, true
+	default:
+		return nil, false
+	}
+}
+
+// compareScalars orders two numbers, or two ISO dates or datetimes (see
+// compareStrings).
+func compareScalars(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return compareStrings(x, y)
+	}
+	return 0, false
+}
+
+// holds reports whether the normalized candidate value v satisfies lit.
+func (lit literal) holds(v any) bool {
+	var result bool
+	switch lit.op {
+	case "=":
+		result = v == lit.value
+	case "!=":
+		result = v != lit.value
+	case "in", "not in":
+		for _, item := range lit.value.([]any) {
+			if item == v {
+				result = true
+				break
+			}
+		}
+		if lit.op == "not in" {
+			result = !result
+		}
+	default:
+		c, ok := compareScalars(v, lit.value)
+		if ok {
+			switch lit.op {
+			case "<":
+				result = c < 0
+			case "<=":
+				result = c <= 0
+			case ">":
+				result = c > 0
+			case ">=":
+				result = c >= 0
+			}
+		}
+	}
+	if lit.negated {
+		return !result
+	}
+	return result
+}
+
+// conjunctionStatus decides whether a conjunction of leaves can match.
+func conjunctionStatus(conj []*node) satStatus {
+	if complementaryPair(conj) != nil {
+		return statusUnsat
+	}
+	byField, unknown := groupLiterals(conj)
+	status := statusSat
+	if unknown {
+		status = statusUnknown
+	}
+	for _, lits := range byField {
+		switch fieldStatus(lits) {
+		case statusUnsat:
+			return statusUnsat
+		case statusUnknown:
+			status = statusUnknown
+		}
+	}
+	return status
+}
+
+// conjunctionConflict returns a minimal unsatisfiable subset of conj as a
+// domain, or nil if the conjunction is not proven unsatisfiable.
+func conjunctionConflict(conj []*node) []any {
+	if pair := complementaryPair(conj); pair != nil {
+		return pair
+	}
+	byField, _ := groupLiterals(conj)
+	for _, lits := range byField {
+		if fieldStatus(lits) != statusUnsat {
+			continue
+		}
+		// Deletion-based minimization: drop every literal that is not
+		// needed to keep the set unsatisfiable.
+		for i := 0; i < len(lits); {
+			without := append(append([]literal{}, lits[:i]...), lits[i+1:]...)
+			if fieldStatus(without) == statusUnsat {
+				lits = without
+				continue
+			}
+			i++
+		}
+		conflict := []any{}
+		for _, lit := range lits {
+			conflict = lit.node.appendTo(conflict)
+		}
+		return conflict
+	}
+	return nil
+}
+
+// complementaryPair looks for a leaf and its exact complement within a
+// conjunction, which works for any operator: either a leaf and the same leaf
+// under '!', or two leaves with complementary operators on a scalar field.
+// The conjunction must come from a tree with resolved negations (see
+// resolveNegations): a '!' written in a domain is not a complement, since
+// neither ('x','<',5) nor ['!',('x','<',5)] matches an unset x. Relational
+// paths are excluded because a leaf on a many2one path is false for both
+// operators when the relation is unset.
+func complementaryPair(conj []*node) []any {
+	seen := make(map[string]*node, len(conj))
+	for _, n := range conj {
+		seen[literalKey(n)] = n
+	}
+	for _, n := range conj {
+		if n.op == "" {
+			field, _ := n.leaf[0].(string)
+			op, _ := n.leaf[1].(string)
+			if _, flips := termOperatorNegation[op]; flips && !scalarPath(field) {
+				continue
+			}
+		}
+		complement, ok := seen[literalKey(n.negate())]
+		if !ok {
+			continue
+		}
+		return complement.appendTo(n.appendTo([]any{}))
+	}
+	return nil
+}
+
+// groupLiterals splits a conjunction by field, preserving first-seen order,
+// and reports whether any element could not be understood.
+func groupLiterals(conj []*node) ([][]literal, bool) {
+	var groups [][]literal
+	index := map[string]int{}
+	unknown := false
+	for _, n := range conj {
+		lit, ok := toLiteral(n)
+		if !ok {
+			unknown = true
+			continue
+		}
+		i, seen := index[lit.field]
+		if !seen {
+			i = len(groups)
+			index[lit.field] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], lit)
+	}
+	return groups, unknown
+}
+
+// fieldStatus decides whether a single field can take a value satisfying
+// every literal. Satisfiability is shown by finding a witness value;
+// unsatisfiability is proven either by exhausting a finite set of allowed
+// values or by an empty range.
+func fieldStatus(lits []literal) satStatus {
+	if len(lits) == 0 {
+		return statusSat
+	}
+	if mixesKinds(lits) {
+		return statusUnknown
+	}
+	for _, candidate := range witnessCandidates(lits) {
+		if satisfiesAll(lits, candidate) {
+			return statusSat
+		}
+	}
+
+	for _, lit := range lits {
+		if !lit.negated && (lit.op == "=" || lit.op == "in") {
+			// Every allowed value was a candidate and none worked.
+			return statusUnsat
+		}
+	}
+	if !satisfiesAll(lits, nil) && emptyRange(lits) {
+		return statusUnsat
+	}
+	return statusUnknown
+}
+
+// mixesKinds reports whether the literals hold values of more than one kind
+// (see scalarKind). The solver cannot relate them: the database casts '1' to
+// match 1, and a date is not ordered against a datetime.
+func mixesKinds(lits []literal) bool {
+	seen := ""
+	for _, lit := range lits {
+		values, isList := lit.value.([]any)
+		if !isList {
+			values = []any{lit.value}
+		}
+		for _, v := range values {
+			kind := scalarKind(v)
+			if kind == "" {
+				continue
+			}
+			if seen != "" && kind != seen {
+				return true
+			}
+			seen = kind
+		}
+	}
+	return false
+}
+
+// scalarKind classifies a normalized value as "number", "date", "datetime"
+// or "text". The unset value and True have no kind.
+func scalarKind(v any) string {
+	switch t := v.(type) {
+	case float64:
+		return "number"
+	case string:
+		if _, ok := parseDateValue(t); !ok {
+			return "text"
+		}
+		if isDateString(t) {
+			return "date"
+		}
+		return "datetime"
+	}
+	return ""
+}
+
+func satisfiesAll(lits []literal, v any) bool {
+	for _, lit := range lits {
+		if !lit.holds(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// witnessCandidates lists values worth trying: the unset value, every value
+// mentioned by the literals and, for numbers, a few values around each bound.
+// Whole numbers are stepped by whole numbers so that a witness also exists
+// for integer fields.
+func witnessCandidates(lits []literal) []any {
+	candidates := []any{nil}
+	spread := float64(len(lits) + 1)
+	for _, lit := range lits {
+		values, isList := lit.value.([]any)
+		if !isList {
+			values = []any{lit.value}
+		}
+		for _, v := range values {
+			candidates = append(candidates, v)
+			f, ok := v.(float64)
+			if !ok {
+				continue
+			}
+			step := 0.5
+			if f == float64(int64(f)) {
+				step = 1
+			}
+			for d := step; d <= spread*step; d += step {
+				candidates = append(candidates, f-d, f+d)
+			}
+		}
+	}
+	return candidates
+}
+
+// emptyRange reports whether the range literals, read as constraints on a
+// set value (unset has already been ruled out), admit no value at all.
+func emptyRange(lits []literal) bool {
+	var lower, upper *literal
+	for i := range lits {
+		lit := rangeLiteral(lits[i])
+		if lit == nil {
+			continue
+		}
+		switch lit.op {
+		case ">", ">=":
+			if lower == nil || tighterBound(*lit, *lower, 1) {
+				lower = lit
+			}
+		case "<", "<=":
+			if upper == nil || tighterBound(*lit, *upper, -1) {
+				upper = lit
+			}
+		}
+	}
+	if lower == nil || upper == nil {
+		return false
+	}
+	c, ok := compareScalars(lower.value, upper.value)
+	if !ok {
+		return false
+	}
+	return c > 0 || (c == 0 && (lower.op == ">" || upper.op == "<"))
+}
+
+// rangeLiteral returns lit as a positive range constraint, flipping negated
+// range operators (valid once the unset value is excluded).
+func rangeLiteral(lit literal) *literal {
+	flipped := map[string]string{"<": ">=", "<=": ">", ">": "<=", ">=": "<"}
+	neg, isRange := flipped[lit.op]
+	if !isRange {
+		return nil
+	}
+	if lit.negated {
+		lit.op, lit.negated = neg, false
+	}
+	return &lit
+}
+
+// tighterBound reports whether a is a stricter bound than b in direction dir
+// (1 for lower bounds, -1 for upper bounds).
+func tighterBound(a, b literal, dir int) bool {
+	c, ok := compareScalars(a.value, b.value)
+	if !ok {
+		return false
+	}
+	if c*dir > 0 {
+		return true
+	}
+	return c == 0 && (a.op == ">" || a.op == "<")
+}
@@ -0,0 +1,117 @@
+package odoosearchdomain
+
+import (
+	"reflect"
+	"testing"
+)
+
+var satisfiabilityPatterns = []struct {
+	domain        string
+	unsatisfiable bool
+	tautology     bool
+	conflicts     [][]any
+}{
+	{"[]", false, true, nil},
+	{"[('state','=','draft')]", false, false, nil},
+	{
+		"[('state','=','draft'),('state','=','done')]", true, false,
+		[][]any{{[]any{"state", "=", "draft"}, []any{"state", "=", "done"}}},
+	},
+	{
+		"[('amount','>',100),('name','ilike','x'),('amount','<',50)]", true, false,
+		[][]any{{[]any{"amount", ">", 100}, []any{"amount", "<", 50}}},
+	},
+	{"[('amount','>',100),('amount','<',150)]", false, false, nil},
+	{"[('amount','>=',100),('amount','<=',100)]", false, false, nil},
+	{
+		"[('amount','>',100),('amount','<=',100)]", true, false,
+		[][]any{{[]any{"amount", ">", 100}, []any{"amount", "<=", 100}}},
+	},
+	// only the conflicting leaves are reported
+	{
+		"[('state','in',['draft','sent']),('state','!=','sale'),('state','not in',['draft','sent'])]", true, false,
+		[][]any{{[]any{"state", "in", []any{"draft", "sent"}}, []any{"state", "not in", []any{"draft", "sent"}}}},
+	},
+	{"[('state','in',['draft','sent']),('state','!=','draft')]", false, false, nil},
+	{"[('state','in',[])]", true, false, [][]any{{[]any{"state", "in", []any{}}}}},
+	{
+		"[('active','=',True),('active','=',False)]", true, false,
+		[][]any{{[]any{"active", "=", true}, []any{"active", "=", false}}},
+	},
+	// every disjunct conflicts
+	{
+		"['|',('a','=',1),('b','=',1),('a','=',2),('b','=',2)]", true, false,
+		[][]any{
+			{[]any{"a", "=", 1}, []any{"a", "=", 2}},
+			{[]any{"b", "=", 1}, []any{"b", "=", 2}},
+		},
+	},
+	{"['|',('a','=',1),('b','=',1),('a','=',2)]", false, false, nil},
+	{
+		"['|','&',('a','=',1),('a','=',2),'&',('b','>',5),('b','<',1)]", true, false,
+		[][]any{
+			{[]any{"a", "=", 1}, []any{"a", "=", 2}},
+			{[]any{"b", ">", 5}, []any{"b", "<", 1}},
+		},
+	},
+	// tautologies
+	{"['|',('state','=','draft'),('state','!=','draft')]", false, true, nil},
+	{"['|',('qty','<',5),'!',('qty','<',5)]", false, false, nil}, // '!' reads as qty >= 5
+	{"['|',('qty','<',5),'|',('qty','>=',5),('qty','=',False)]", false, true, nil},
+	{
+		"[('qty','=',False),'!',('qty','>=',5)]", true, false,
+		[][]any{{[]any{"qty", "=", false}, []any{"qty", "<", 5}}},
+	},
+	{"['|',('name','=like','a%'),'!',('name','=like','a%')]", false, false, nil},
+	{"['|','|',('name','=like','a%'),'!',('name','=like','a%'),('name','=',False)]", false, true, nil},
+	{"['|',('qty','<',5),('qty','>=',5)]", false, false, nil}, // unset qty matches neither
+	// unset values: != matches them, ranges do not
+	{"[('x','!=','foo'),('x','=',False)]", false, false, nil},
+	{
+		"[('x','>',0),('x','=',None)]", true, false,
+		[][]any{{[]any{"x", ">", 0}, []any{"x", "=", nil}}},
+	},
+	// a date is not ordered against a datetime
+	{"[('d','<=','2024-01-31'),('d','>','2024-01-31 12:00:00')]", false, false, nil},
+	{"[('d','=','2024-01-31 12:00:00'),('d','<=','2024-01-31')]", false, false, nil},
+	// strings are ordered by the database collation, not bytewise
+	{"[('name','>','a'),('name','<','B')]", false, false, nil},
+	{"[('name','>','a'),('name','=','b')]", false, false, nil},
+	// the database casts '1' to match 1
+	{"[('id','=','1'),('id','=',1)]", false, false, nil},
+	{"[('id','in',['1','2']),('id','not in',[1,2])]", false, false, nil},
+	// unknown operators and relational paths are never used as evidence
+	{"[('name','like','a'),('name','like','b')]", false, false, nil},
+	{"['|',('name','like','a'),('name','not like','a')]", false, true, nil},
+	{
+		"[('name','like','a'),('name','not like','a')]", true, false,
+		[][]any{{[]any{"name", "like", "a"}, []any{"name", "not like", "a"}}},
+	},
+	{"[('partner_id.name','=','a'),('partner_id.name','!=','a')]", false, false, nil},
+	{"[('line_ids.qty','=',1),('line_ids.qty','=',2)]", false, false, nil},
+	{
+		"[('date.month_number','=',1),('date.month_number','=',2)]", true, false,
+		[][]any{{[]any{"date.month_number", "=", 1}, []any{"date.month_number", "=", 2}}},
+	},
+}
+
+func TestCheckSatisfiability(t *testing.T) {
+	for i, pattern := range satisfiabilityPatterns {
+		terms, err := ParseDomain(pattern.domain)
+		if err != nil {
+			t.Fatalf("[%d] parse %s: %v", i, pattern.domain, err)
+		}
+		result, err := CheckSatisfiability(terms)
+		if err != nil {
+			t.Errorf("[%d] %s: unexpected error %v", i, pattern.domain, err)
+			continue
+		}
+		if result.Unsatisfiable != pattern.unsatisfiable || result.Tautology != pattern.tautology {
+			t.Errorf("[%d] %s\nexpected unsatisfiable=%v tautology=%v\n     got unsatisfiable=%v tautology=%v",
+				i, pattern.domain, pattern.unsatisfiable, pattern.tautology, result.Unsatisfiable, result.Tautology)
+		}
+		if !reflect.DeepEqual(pattern.conflicts, result.Conflicts) {
+			t.Errorf("[%d] %s\nexpected conflicts: %v\n     got conflicts: %v", i, pattern.domain, pattern.conflicts, result.Conflicts)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package odoosearchdomain
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MatchStruct is Match for records held in Go structs, pointers to structs
+// or maps. Struct fields are resolved by their `odoo:"name"` tag, then by
+// their json tag, then by their Go name, which also matches in lower case.
+// Nested structs, pointers and slices of them are followed for dotted paths
+// and 'any' subdomains. Reflection metadata is cached per type.
+func MatchStruct(domain []any, record any) (bool, error) {
+	p, err := Compile(domain)
+	if err != nil {
+		return false, err
+	}
+	return p.MatchStruct(record)
+}
+
+// MatchStruct reports whether a struct, pointer to struct or map satisfies
+// the predicate.
+func (p *Predicate) MatchStruct(record any) (bool, error) {
+	v, err := recordValue(record)
+	if err != nil {
+		return false, err
+	}
+	return p.match(v)
+}
+
+// Filter returns the records satisfying a domain, compiled once. Records may
+// be structs, pointers to structs or maps.
+func Filter[T any](domain []any, records []T) ([]T, error) {
+	p, err := Compile(domain)
+	if err != nil {
+		return nil, err
+	}
+	var out []T
+	for _, record := range records {
+		ok, err := p.MatchStruct(record)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, record)
+		}
+	}
+	return out, nil
+}
+
+// ============================================================
+// Reflection metadata
+// ============================================================
+
+// structFields maps domain field names to struct field indexes.
+type structFields map[string][]int
+
+// structFieldCache holds the structFields of every struct type seen.
+var structFieldCache sync.Map // reflect.Type -> structFields
+
+func fieldsOf(t reflect.Type) structFields {
+	if cached, ok := structFieldCache.Load(t); ok {
+		return cached.(structFields)
+	}
+	fields := structFields{}
+	byPriority := map[string]int{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name, priority := fieldName(f)
+		if name == "" {
+			continue
+		}
+		// Prefer explicit tags, then shallower fields.
+		if current, seen := byPriority[name]; seen && (current < priority || (current == priority && len(fields[name]) <= len(f.Index))) {
+			continue
+		}
+		fields[name] = f.Index
+		byPriority[name] = priority
+	}
+	// Go names also match case-insensitively, e.g. ID as "id".
+	for name, index := range fields {
+		lower := strings.ToLower(name)
+		if _, taken := fields[lower]; !taken && byPriority[name] == 2 {
+			fields[lower] = index
+		}
+	}
+	cached, _ := structFieldCache.LoadOrStore(t, fields)
+	return cached.(structFields)
+}
+
+// fieldName returns the domain name of a struct field and its priority:
+// 0 for an odoo tag, 1 for a json tag, 2 for the Go name.
+func fieldName(f reflect.StructField) (string, int) {
+	if tag, ok := f.Tag.Lookup("odoo"); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return "", 0
+		}
+		if name != "" {
+			return name, 0
+		}
+	}
+	if tag, ok := f.Tag.Lookup("json"); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return "", 0
+		}
+		if name != "" {
+			return name, 1
+		}
+	}
+	return f.Name, 2
+}
+
+// lookupStructField returns a field of a struct record. Nil pointers read as
+// unset values and pointers to scalars are dereferenced.
+func lookupStructField(rv reflect.Value, name, path string) (any, error) {
+	index, ok := fieldsOf(rv.Type())[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q in %s", ErrFieldNotFound, name, path)
+	}
+	fv, err := rv.FieldByIndexErr(index)
+	if err != nil {
+		// A nil embedded pointer: the promoted field is unset.
+		return nil, nil
+	}
+	for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return nil, nil
+		}
+		fv = fv.Elem()
+	}
+	return fv.Interface(), nil
+}
+
+// recordValue dereferences a record given as a pointer to a struct or map.
+func recordValue(record any) (any, error) {
+	rv := reflect.ValueOf(record)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("%w: nil record", ErrIncompatibleTypes)
+		}
+		rv = rv.Elem()
+	}
+	if !isRecordValue(rv) {
+		return nil, fmt.Errorf("%w: %T is not a record", ErrIncompatibleTypes, record)
+	}
+	return rv.Interface(), nil
+}
+
+// isRecordValue reports whether a value can hold fields: a map with string
+// keys or a struct other than time.Time.
+func isRecordValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Map:
+		return rv.Type().Key().Kind() == reflect.String
+	case reflect.Struct:
+		return rv.Type() != reflect.TypeFor[time.Time]()
+	}
+	return false
+}
+
+// asList returns the elements of a slice or array other than a byte slice.
+func asList(v any) ([]any, bool) {
+	if list, ok := v.([]any); ok {
+		return list, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	list := make([]any, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list, true
+}
@@ -0,0 +1,142 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testCountry struct {
+	ID   int    `odoo:"id"`
+	Code string `json:"code"`
+}
+
+type testPartner struct {
+	ID      int          `odoo:"id"`
+	Name    string       `odoo:"name" json:"display_name"`
+	Country *testCountry `odoo:"country_id"`
+	Parent  *testPartner `odoo:"parent_id"`
+}
+
+type testAudit struct {
+	CreateDate time.Time `json:"create_date"`
+	Secret     string    `json:"-"`
+}
+
+type testState string
+
+type testLine struct {
+	ID   int
+	Name string  `json:"name,omitempty"`
+	Qty  float32 `odoo:"product_uom_qty"`
+}
+
+type testOrder struct {
+	testAudit
+	ID      int         `odoo:"id"`
+	Name    string      `odoo:"name"`
+	State   testState   `odoo:"state"`
+	Amount  *float64    `odoo:"amount_total"`
+	Locked  bool        `odoo:"locked"`
+	Partner testPartner `odoo:"partner_id"`
+	Lines   []*testLine `odoo:"order_line"`
+	TagIDs  []int64     `odoo:"tag_ids"`
+	Extra   map[string]any
+	private string //nolint:unused
+}
+
+func TestMatchStruct(t *testing.T) {
+	amount := 120.0
+	order := testOrder{
+		testAudit: testAudit{CreateDate: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), Secret: "x"},
+		ID:        3,
+		Name:      "SO003",
+		State:     "sale",
+		Amount:    &amount,
+		Partner:   testPartner{ID: 9, Name: "Deco Addict", Country: &testCountry{ID: 21, Code: "BE"}},
+		Lines:     []*testLine{{ID: 1, Name: "Desk", Qty: 2}, nil, {ID: 2, Name: "Chair", Qty: 5}},
+		TagIDs:    []int64{4, 5},
+		Extra:     map[string]any{"priority": "1"},
+	}
+	tests := []struct {
+		domain   string
+		expected bool
+	}{
+		{"[('state','=','sale'),('name','=like','SO%')]", true},
+		{"[('amount_total','>',100)]", true},
+		{"[('locked','=',False)]", true},
+		{"[('create_date','>=','2024-03-01')]", true},
+		{"[('partner_id','=',9)]", true},
+		{"[('partner_id.name','=','Deco Addict')]", true},
+		{"[('partner_id.country_id.code','=','BE')]", true},
+		{"[('partner_id.parent_id','=',False)]", true},
+		{"[('partner_id.parent_id.name','=','x')]", false},
+		{"[('order_line.product_uom_qty','>',4)]", true},
+		{"[('order_line','any',[('name','=','Desk'),('product_uom_qty','>',4)])]", false},
+		{"[('order_line','in',[2])]", true},
+		{"[('tag_ids','in',[5,6])]", true},
+		{"[('tag_ids','not in',[5])]", false},
+		{"[('Extra.priority','=','1')]", true},
+	}
+	for _, tt := range tests {
+		domain, err := ParseDomain(tt.domain)
+		if err != nil {
+			t.Fatalf("parse %s: %v", tt.domain, err)
+		}
+		for _, record := range []any{order, &order} {
+			got, err := MatchStruct(domain, record)
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.domain, err)
+				continue
+			}
+			if got != tt.expected {
+				t.Errorf("%s: expected %v, got %v", tt.domain, tt.expected, got)
+			}
+		}
+	}
+
+	// Unknown, ignored and unexported fields are not found.
+	for _, field := range []string{"display_name", "Secret", "private", "Amount"} {
+		if _, err := MatchStruct([]any{[]any{field, "=", 1}}, order); !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("%s: expected ErrFieldNotFound, got %v", field, err)
+		}
+	}
+	if _, err := MatchStruct([]any{}, 5); !errors.Is(err, ErrIncompatibleTypes) {
+		t.Errorf("expected ErrIncompatibleTypes for a non-record, got %v", err)
+	}
+	if _, err := MatchStruct([]any{}, (*testOrder)(nil)); !errors.Is(err, ErrIncompatibleTypes) {
+		t.Errorf("expected ErrIncompatibleTypes for a nil record, got %v", err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	partners := []testPartner{
+		{ID: 1, Name: "Azure Interior", Country: &testCountry{Code: "US"}},
+		{ID: 2, Name: "Deco Addict", Country: &testCountry{Code: "BE"}},
+		{ID: 3, Name: "Gemini Furniture"},
+	}
+	domain, _ := ParseDomain("['|',('country_id.code','=','BE'),('country_id','=',False)]")
+	got, err := Filter(domain, partners)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(got, []testPartner{partners[1], partners[2]}) {
+		t.Errorf("unexpected result %v", got)
+	}
+
+	maps := []map[string]any{{"id": 1}, {"id": 2}}
+	gotMaps, err := Filter([]any{[]any{"id", ">", 1}}, maps)
+	if err != nil || len(gotMaps) != 1 || gotMaps[0]["id"] != 2 {
+		t.Errorf("unexpected result %v, %v", gotMaps, err)
+	}
+}
+
+func BenchmarkMatchStruct(b *testing.B) {
+	domain, _ := ParseDomain("[('state','=','sale'),('partner_id.country_id.code','=','BE')]")
+	p, _ := Compile(domain)
+	order := testOrder{State: "sale", Partner: testPartner{Country: &testCountry{Code: "BE"}}}
+	for b.Loop() {
+		p.MatchStruct(&order)
+	}
+}
@@ -0,0 +1,96 @@
+package odoosearchdomain
+
+import (
+	"fmt"
+	"time"
+)
+
+// temporal is a date or datetime value. A date holds midnight UTC of its
+// day; a datetime is an instant.
+type temporal struct {
+	t    time.Time
+	date bool
+}
+
+// temporalOf interprets a value as a date or datetime: a time.Time is a
+// datetime, a 'YYYY-MM-DD' string a date and a 'YYYY-MM-DD HH:MM:SS' string
+// a datetime in UTC, as Odoo stores them.
+func temporalOf(v any) (temporal, bool) {
+	switch x := v.(type) {
+	case time.Time:
+		return temporal{t: x}, true
+	case string:
+		t, ok := parseDateValue(x)
+		return temporal{t: t, date: len(x) == len("2006-01-02")}, ok
+	}
+	return temporal{}, false
+}
+
+// compareTemporal orders a record value a against a domain value b. Two
+// dates compare by day and two datetimes as instants. A datetime bound on a
+// date field is cut to its day in loc. A date bound on a datetime field
+// stands for the start of that day in loc, or for its end when dayEnd is
+// set, which '>' and '<=' use so that the bound covers the whole day.
+func compareTemporal(a, b temporal, loc *time.Location, dayEnd bool) int {
+	if loc == nil {
+		loc = time.UTC
+	}
+	switch {
+	case a.date && !b.date:
+		y, m, d := b.t.In(loc).Date()
+		return a.t.Compare(time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+	case !a.date && b.date:
+		y, m, d := b.t.Date()
+		bound := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		if dayEnd {
+			bound = time.Date(y, m, d+1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+		}
+		return a.t.Compare(bound)
+	}
+	return a.t.Compare(b.t)
+}
+
+// datePart extracts a date granularity from a date or datetime value. A
+// datetime is first converted to loc, as Odoo converts to the user's
+// timezone; a date has no timezone. An unset value stays unset.
+// day_of_week counts from 0 for Sunday, as PostgreSQL's dow does.
+func datePart(field, granularity string, v any, loc *time.Location) (any, error) {
+	if evalScalar(v) == nil {
+		return nil, nil
+	}
+	value, ok := temporalOf(evalScalar(v))
+	if !ok {
+		return nil, fmt.Errorf("%w: %s needs a date or datetime, got %v (%T)", ErrIncompatibleTypes, field, v, v)
+	}
+	t := value.t
+	if !value.date {
+		if loc == nil {
+			loc = time.UTC
+		}
+		t = t.In(loc)
+	}
+	var part int
+	switch granularity {
+	case "year_number":
+		part = t.Year()
+	case "quarter_number":
+		part = (int(t.Month())-1)/3 + 1
+	case "month_number":
+		part = int(t.Month())
+	case "iso_week_number":
+		_, part = t.ISOWeek()
+	case "day_of_week":
+		part = int(t.Weekday())
+	case "day_of_month":
+		part = t.Day()
+	case "day_of_year":
+		part = t.YearDay()
+	case "hour_number":
+		part = t.Hour()
+	case "minute_number":
+		part = t.Minute()
+	case "second_number":
+		part = t.Second()
+	}
+	return float64(part), nil
+}
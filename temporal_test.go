@@ -0,0 +1,65 @@
+package odoosearchdomain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchTimezones(t *testing.T) {
+	plus2 := time.FixedZone("UTC+2", 2*3600)
+	record := map[string]any{
+		"create_date": "2024-03-15 23:30:00", // UTC, 2024-03-16 01:30 in UTC+2
+		"write_date":  time.Date(2024, 3, 16, 1, 30, 0, 0, plus2),
+		"date":        "2024-03-16",
+	}
+	tests := []struct {
+		domain   []any
+		loc      *time.Location
+		expected bool
+	}{
+		// A date bound on a datetime covers that day in the user's timezone.
+		{[]any{[]any{"create_date", ">=", "2024-03-15"}}, nil, true},
+		{[]any{[]any{"create_date", "<", "2024-03-16"}}, nil, true},
+		{[]any{[]any{"create_date", "<=", "2024-03-15"}}, nil, true},
+		{[]any{[]any{"create_date", ">", "2024-03-15"}}, nil, false},
+		{[]any{[]any{"create_date", "=", "2024-03-15"}}, nil, false},
+		{[]any{[]any{"create_date", ">=", "2024-03-16"}}, plus2, true},
+		{[]any{[]any{"create_date", "<=", "2024-03-15"}}, plus2, false},
+		{[]any{[]any{"create_date", ">", "2024-03-15"}}, plus2, true},
+		{[]any{"!", []any{"create_date", ">", "2024-03-15"}}, plus2, false},
+		{[]any{[]any{"write_date", "<", "2024-03-16"}}, nil, true},
+		{[]any{[]any{"write_date", "<", "2024-03-16"}}, plus2, false},
+
+		// Datetimes compare as instants, whatever their zone or layout.
+		{[]any{[]any{"create_date", "=", "2024-03-15T23:30:00"}}, plus2, true},
+		{[]any{[]any{"write_date", "=", "2024-03-15 23:30:00"}}, nil, true},
+		{[]any{[]any{"create_date", "=", time.Date(2024, 3, 16, 1, 30, 0, 0, plus2)}}, nil, true},
+		{[]any{[]any{"create_date", "in", []any{"2024-03-15 23:30:00", "2024-03-16 23:30:00"}}}, plus2, true},
+		{[]any{[]any{"write_date", ">", "2024-03-15 23:00:00"}}, nil, true},
+
+		// A datetime bound on a date is cut to its day in the user's timezone.
+		{[]any{[]any{"date", "=", "2024-03-15 23:30:00"}}, nil, false},
+		{[]any{[]any{"date", "=", "2024-03-15 23:30:00"}}, plus2, true},
+		{[]any{[]any{"date", ">", time.Date(2024, 3, 16, 1, 0, 0, 0, plus2)}}, nil, true},
+		{[]any{[]any{"date", ">", time.Date(2024, 3, 16, 1, 0, 0, 0, plus2)}}, plus2, false},
+		{[]any{[]any{"date", "<=", "2024-03-16"}}, plus2, true},
+
+		// Date parts of datetimes are read in the user's timezone; dates
+		// have no timezone.
+		{[]any{[]any{"create_date.day_of_month", "=", 15}}, nil, true},
+		{[]any{[]any{"create_date.day_of_month", "=", 16}}, plus2, true},
+		{[]any{[]any{"create_date.hour_number", "=", 1}}, plus2, true},
+		{[]any{[]any{"write_date.hour_number", "=", 23}}, nil, true},
+		{[]any{[]any{"date.day_of_month", "=", 16}}, time.FixedZone("UTC-5", -5*3600), true},
+	}
+	for _, tt := range tests {
+		got, err := MatchWithOptions(tt.domain, record, MatchOptions{Location: tt.loc})
+		if err != nil {
+			t.Errorf("%v in %v: unexpected error %v", tt.domain, tt.loc, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%v in %v: expected %v, got %v", tt.domain, tt.loc, tt.expected, got)
+		}
+	}
+}
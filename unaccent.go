@@ -0,0 +1,120 @@
+package odoosearchdomain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// unaccentTable maps accented Latin letters, ligatures and special forms to
+// their plain ASCII spelling, after PostgreSQL's unaccent rules for the
+// Latin-1 Supplement, Latin Extended-A and the letters of Latin Extended-B
+// in common use.
+var unaccentTable = buildUnaccentTable(map[string]string{
+	"A":  "ÀÁÂÃÄÅĀĂĄǍǞǠǺȀȂȦ",
+	"a":  "àáâãäåāăąǎǟǡǻȁȃȧ",
+	"C":  "ÇĆĈĊČ",
+	"c":  "çćĉċč",
+	"D":  "ÐĎĐ",
+	"d":  "ðďđ",
+	"E":  "ÈÉÊËĒĔĖĘĚȄȆȨ",
+	"e":  "èéêëēĕėęěȅȇȩ",
+	"G":  "ĜĞĠĢǦǴ",
+	"g":  "ĝğġģǧǵ",
+	"H":  "ĤĦȞ",
+	"h":  "ĥħȟ",
+	"I":  "ÌÍÎÏĨĪĬĮİǏȈȊ",
+	"i":  "ìíîïĩīĭįıǐȉȋ",
+	"J":  "Ĵ",
+	"j":  "ĵǰ",
+	"K":  "ĶǨ",
+	"k":  "ķĸǩ",
+	"L":  "ĹĻĽĿŁ",
+	"l":  "ĺļľŀł",
+	"N":  "ÑŃŅŇŊǸ",
+	"n":  "ñńņňŉŋǹ",
+	"O":  "ÒÓÔÕÖØŌŎŐƠǑǪǬǾȌȎȮ",
+	"o":  "òóôõöøōŏőơǒǫǭǿȍȏȯ",
+	"R":  "ŔŖŘȐȒ",
+	"r":  "ŕŗřȑȓ",
+	"S":  "ŚŜŞŠȘ",
+	"s":  "śŝşšșſ",
+	"T":  "ŢŤŦȚ",
+	"t":  "ţťŧț",
+	"U":  "ÙÚÛÜŨŪŬŮŰŲƯǓǕǗǙǛȔȖ",
+	"u":  "ùúûüũūŭůűųưǔǖǘǚǜȕȗ",
+	"W":  "Ŵ",
+	"w":  "ŵ",
+	"Y":  "ÝŶŸȲ",
+	"y":  "ýÿŷȳ",
+	"Z":  "ŹŻŽ",
+	"z":  "źżž",
+	"AE": "ÆǢǼ",
+	"ae": "æǣǽ",
+	"IJ": "Ĳ",
+	"ij": "ĳ",
+	"OE": "Œ",
+	"oe": "œ",
+	"ss": "ß",
+	"TH": "Þ",
+	"th": "þ",
+})
+
+func buildUnaccentTable(groups map[string]string) map[rune]string {
+	table := map[rune]string{}
+	for plain, accented := range groups {
+		for _, r := range accented {
+			table[r] = plain
+		}
+	}
+	return table
+}
+
+// unaccent replaces the accented Latin letters of s by their plain
+// spelling. It returns s itself when there is nothing to replace.
+func unaccent(s string) string {
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if _, ok := unaccentTable[r]; ok {
+			break
+		}
+		i += size
+	}
+	if i == len(s) {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s) + 8)
+	sb.WriteString(s[:i])
+	for _, r := range s[i:] {
+		if plain, ok := unaccentTable[r]; ok {
+			sb.WriteString(plain)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// textFolder normalizes strings for '=' and 'in' under
+// MatchOptions.FoldEquality: accents removed with Unaccent, then case
+// folded with the case mapping, or with Unicode simple case folding.
+type textFolder struct {
+	unaccent    bool
+	caseMapping unicode.SpecialCase
+}
+
+// key returns the folded form of s. A nil folder leaves s unchanged.
+func (f *textFolder) key(s string) string {
+	if f == nil {
+		return s
+	}
+	if f.unaccent {
+		s = unaccent(s)
+	}
+	if f.caseMapping != nil {
+		return strings.Map(func(r rune) rune { return foldRune(f.caseMapping, r) }, s)
+	}
+	return strings.Map(simpleFoldKey, s)
+}
@@ -0,0 +1,266 @@
+package odoosearchdomain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SkipNested can be returned by a WalkFunc visiting an 'any' or 'not any'
+// leaf to skip the leaves of its nested domain. It is not returned as an
+// error by Walk.
+var SkipNested = errors.New("skip nested domain")
+
+// WalkItem describes one element of a domain visited by Walk or passed to a
+// RewriteFunc.
+type WalkItem struct {
+	// Connector is "&", "|" or "!" for connectors and empty for leaves.
+	Connector string
+	// Leaf is the term ([]any{field, operator, value}) for leaves.
+	Leaf []any
+	// Depth is the number of connectors and nested domains enclosing the
+	// element. Elements joined by the implicit top-level AND have depth 0.
+	Depth int
+	// Path indexes the element in the nested []any structure: [i] is the
+	// i-th element of the domain, [i, 2, j] the j-th element of the domain
+	// held in the value of the leaf at [i].
+	Path []int
+	// Scope is the dotted field path of the 'any'/'not any' leaves the
+	// element is nested under, or empty at the top level.
+	Scope string
+}
+
+// WalkFunc is called by Walk for every connector and leaf.
+type WalkFunc func(item WalkItem) error
+
+// Walk visits every connector and leaf of a domain in prefix order,
+// descending into the nested domains of 'any' and 'not any' leaves right
+// after visiting the leaf itself. If fn returns an error other than
+// SkipNested, Walk stops and returns it.
+func Walk(terms []any, fn WalkFunc) error {
+	return walkDomain(terms, fn, 0, nil, "")
+}
+
+func walkDomain(terms []any, fn WalkFunc, depth int, path []int, scope string) error {
+	pos := 0
+	for pos < len(terms) {
+		count, err := walkAt(terms, pos, fn, depth, path, scope)
+		if err != nil {
+			return err
+		}
+		pos += count
+	}
+	return nil
+}
+
+// walkAt visits the expression rooted at terms[pos] and returns the number
+// of elements it consumed.
+func walkAt(terms []any, pos int, fn WalkFunc, depth int, path []int, scope string) (int, error) {
+	if pos >= len(terms) {
+		return 0, fmt.Errorf("%w: unexpected end of domain", ErrSyntax)
+	}
+	here := appendPath(path, pos)
+
+	switch terms[pos] {
+	case "&", "|", "!":
+		op := terms[pos].(string)
+		if err := fn(WalkItem{Connector: op, Depth: depth, Path: here, Scope: scope}); err != nil {
+			return 0, err
+		}
+		arity, arityErr := 2, ErrNotEnoughAndOrTerms
+		if op == "!" {
+			arity, arityErr = 1, ErrNotEnoughNotTerms
+		}
+		consumed := 1
+		for range arity {
+			if pos+consumed >= len(terms) {
+				return 0, arityErr
+			}
+			count, err := walkAt(terms, pos+consumed, fn, depth+1, path, scope)
+			if err != nil {
+				return 0, err
+			}
+			consumed += count
+		}
+		return consumed, nil
+
+	default:
+		term, err := leafTerm(terms[pos])
+		if err != nil {
+			return 0, err
+		}
+		err = fn(WalkItem{Leaf: term, Depth: depth, Path: here, Scope: scope})
+		if errors.Is(err, SkipNested) {
+			return 1, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if nested, field, ok := nestedDomain(term); ok {
+			if err := walkDomain(nested, fn, depth+1, appendPath(here, 2), joinScope(scope, field)); err != nil {
+				return 0, err
+			}
+		}
+		return 1, nil
+	}
+}
+
+// RewriteFunc returns the replacement for a leaf as a domain: nil or an
+// empty domain drops the leaf, []any{item.Leaf} keeps it, and any other
+// domain replaces it (several expressions are joined by AND). Leaves holding
+// a nested domain are passed after their nested domain has been rewritten.
+type RewriteFunc func(item WalkItem) ([]any, error)
+
+// Rewrite returns a copy of the domain with every leaf, including leaves of
+// nested 'any' and 'not any' domains, replaced by the result of fn. The input
+// is not modified.
+//
+// Connector arity is repaired when leaves are dropped: an '&' or '|' left
+// with a single operand is replaced by that operand, and a connector left
+// without operands disappears. Dropping a leaf therefore removes its
+// condition rather than replacing it by TRUE or FALSE.
+func Rewrite(terms []any, fn RewriteFunc) ([]any, error) {
+	return rewriteDomain(terms, fn, 0, nil, "")
+}
+
+func rewriteDomain(terms []any, fn RewriteFunc, depth int, path []int, scope string) ([]any, error) {
+	out := []any{}
+	pos := 0
+	for pos < len(terms) {
+		if isConnector(terms[pos]) {
+			n, count, err := rewriteAt(terms, pos, fn, depth, path, scope)
+			if err != nil {
+				return []any{}, err
+			}
+			if n != nil {
+				out = n.appendTo(out)
+			}
+			pos += count
+			continue
+		}
+		// Top-level leaves are spliced so that a replacement keeps its
+		// implicit AND instead of gaining explicit connectors.
+		replacement, err := rewriteLeaf(terms[pos], fn, depth, appendPath(path, pos), scope)
+		if err != nil {
+			return []any{}, err
+		}
+		if err := validateReplacement(replacement); err != nil {
+			return []any{}, err
+		}
+		out = append(out, replacement...)
+		pos++
+	}
+	return out, nil
+}
+
+// rewriteAt rewrites the expression rooted at terms[pos]. A nil node means
+// the whole expression was dropped.
+func rewriteAt(terms []any, pos int, fn RewriteFunc, depth int, path []int, scope string) (*node, int, error) {
+	if pos >= len(terms) {
+		return nil, 0, fmt.Errorf("%w: unexpected end of domain", ErrSyntax)
+	}
+
+	switch terms[pos] {
+	case "&", "|", "!":
+		op := terms[pos].(string)
+		arity, arityErr := 2, ErrNotEnoughAndOrTerms
+		if op == "!" {
+			arity, arityErr = 1, ErrNotEnoughNotTerms
+		}
+		var operands []*node
+		consumed := 1
+		for range arity {
+			if pos+consumed >= len(terms) {
+				return nil, 0, arityErr
+			}
+			n, count, err := rewriteAt(terms, pos+consumed, fn, depth+1, path, scope)
+			if err != nil {
+				return nil, 0, err
+			}
+			if n != nil {
+				operands = append(operands, n)
+			}
+			consumed += count
+		}
+		switch {
+		case len(operands) == 0:
+			return nil, consumed, nil
+		case op != "!" && len(operands) == 1:
+			return operands[0], consumed, nil
+		default:
+			return &node{op: op, children: operands}, consumed, nil
+		}
+
+	default:
+		replacement, err := rewriteLeaf(terms[pos], fn, depth, appendPath(path, pos), scope)
+		if err != nil {
+			return nil, 0, err
+		}
+		n, err := buildTree(replacement)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid replacement for leaf at %v: %w", appendPath(path, pos), err)
+		}
+		return n, 1, nil
+	}
+}
+
+func rewriteLeaf(v any, fn RewriteFunc, depth int, path []int, scope string) ([]any, error) {
+	term, err := leafTerm(v)
+	if err != nil {
+		return nil, err
+	}
+	term = append([]any{}, term...)
+	if nested, field, ok := nestedDomain(term); ok {
+		rewritten, err := rewriteDomain(nested, fn, depth+1, appendPath(path, 2), joinScope(scope, field))
+		if err != nil {
+			return nil, err
+		}
+		term[2] = rewritten
+	}
+	return fn(WalkItem{Leaf: term, Depth: depth, Path: path, Scope: scope})
+}
+
+func validateReplacement(replacement []any) error {
+	if _, err := validateDomain(replacement); err != nil {
+		return fmt.Errorf("invalid replacement: %w", err)
+	}
+	for _, item := range replacement {
+		if isConnector(item) {
+			continue
+		}
+		if _, err := leafTerm(item); err != nil {
+			return fmt.Errorf("invalid replacement: %w", err)
+		}
+	}
+	return nil
+}
+
+func isConnector(v any) bool {
+	return v == "&" || v == "|" || v == "!"
+}
+
+// nestedDomain returns the nested domain held by an 'any' or 'not any' term
+// together with the term's field.
+func nestedDomain(term []any) ([]any, string, bool) {
+	op, _ := term[1].(string)
+	if op != "any" && op != "not any" {
+		return nil, "", false
+	}
+	field, isString := term[0].(string)
+	nested, isList := term[2].([]any)
+	return nested, field, isString && isList
+}
+
+func joinScope(scope, field string) string {
+	if scope == "" {
+		return field
+	}
+	return scope + "." + field
+}
+
+// appendPath returns path extended by index without sharing storage with
+// path, so items handed to callbacks can be retained safely.
+func appendPath(path []int, index int) []int {
+	out := make([]int, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, index)
+}
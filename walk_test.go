@@ -0,0 +1,122 @@
+package odoosearchdomain
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	terms, _ := ParseDomain("[('name','=','x'),'|',('a','=',1),'!',('b','=',2),('line_ids','any',[('qty','>',1),('product_id','not any',[('type','=','service')])])]")
+	var visited []string
+	err := Walk(terms, func(item WalkItem) error {
+		label := item.Connector
+		if label == "" {
+			label = fmt.Sprint(item.Leaf[0])
+		}
+		visited = append(visited, fmt.Sprintf("%s d=%d p=%v s=%q", label, item.Depth, item.Path, item.Scope))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{
+		`name d=0 p=[0] s=""`,
+		`| d=0 p=[1] s=""`,
+		`a d=1 p=[2] s=""`,
+		`! d=1 p=[3] s=""`,
+		`b d=2 p=[4] s=""`,
+		`line_ids d=0 p=[5] s=""`,
+		`qty d=1 p=[5 2 0] s="line_ids"`,
+		`product_id d=1 p=[5 2 1] s="line_ids"`,
+		`type d=2 p=[5 2 1 2 0] s="line_ids.product_id"`,
+	}
+	if !reflect.DeepEqual(expected, visited) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, visited)
+	}
+
+	visited = nil
+	Walk(terms, func(item WalkItem) error {
+		if item.Connector == "" {
+			visited = append(visited, fmt.Sprint(item.Leaf[0]))
+		}
+		if item.Connector == "" && item.Leaf[1] == "any" {
+			return SkipNested
+		}
+		return nil
+	})
+	if !reflect.DeepEqual([]string{"name", "a", "b", "line_ids"}, visited) {
+		t.Errorf("SkipNested did not skip nested leaves: %v", visited)
+	}
+
+	if err := Walk([]any{"|", leafA}, func(WalkItem) error { return nil }); err != ErrNotEnoughAndOrTerms {
+		t.Errorf("expected ErrNotEnoughAndOrTerms, got %v", err)
+	}
+}
+
+var rewritePatterns = []struct {
+	domain   string
+	drop     string // field whose leaves are dropped
+	expected []any
+}{
+	{"[('a','=',1),('b','=',2)]", "a", []any{[]any{"b", "=", 2}}},
+	{"['|',('a','=',1),('b','=',2)]", "a", []any{[]any{"b", "=", 2}}},
+	{"['!',('a','=',1),('b','=',2)]", "a", []any{[]any{"b", "=", 2}}},
+	{"['|','&',('a','=',1),('a','=',2),('b','=',2)]", "a", []any{[]any{"b", "=", 2}}},
+	{"['&','|',('a','=',1),('c','=',3),('b','=',2)]", "a", []any{"&", []any{"c", "=", 3}, []any{"b", "=", 2}}},
+	{"[('l','any',['|',('a','=',1),('b','=',2)])]", "a", []any{[]any{"l", "any", []any{[]any{"b", "=", 2}}}}},
+	{"[('a','=',1)]", "a", []any{}},
+}
+
+func TestRewriteDrop(t *testing.T) {
+	for i, pattern := range rewritePatterns {
+		terms, _ := ParseDomain(pattern.domain)
+		got, err := Rewrite(terms, func(item WalkItem) ([]any, error) {
+			if item.Leaf[0] == pattern.drop {
+				return nil, nil
+			}
+			return []any{item.Leaf}, nil
+		})
+		if err != nil {
+			t.Errorf("[%d] unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(pattern.expected, got) {
+			t.Errorf("[%d] %s\nexpected: %v\n     got: %v", i, pattern.domain, pattern.expected, got)
+		}
+	}
+}
+
+func TestRewriteExpand(t *testing.T) {
+	terms, _ := ParseDomain("[('phone','=','1'),'!',('phone','=','2')]")
+	got, err := Rewrite(terms, func(item WalkItem) ([]any, error) {
+		value := item.Leaf[2]
+		return []any{"|", []any{"phone", "=", value}, []any{"mobile", "=", value}}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []any{
+		"|", []any{"phone", "=", "1"}, []any{"mobile", "=", "1"},
+		"!", "|", []any{"phone", "=", "2"}, []any{"mobile", "=", "2"},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected: %v\n     got: %v", expected, got)
+	}
+
+	// several expressions replacing an operand are joined by AND
+	terms, _ = ParseDomain("['|',('a','=',1),('b','=',2)]")
+	got, _ = Rewrite(terms, func(item WalkItem) ([]any, error) {
+		if item.Leaf[0] == "a" {
+			return []any{leafA, leafC}, nil
+		}
+		return []any{item.Leaf}, nil
+	})
+	expected = []any{"|", "&", leafA, leafC, leafB}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected: %v\n     got: %v", expected, got)
+	}
+
+	if _, err := Rewrite(terms, func(WalkItem) ([]any, error) { return []any{"|", leafA}, nil }); err == nil {
+		t.Errorf("expected an error for an invalid replacement")
+	}
+}